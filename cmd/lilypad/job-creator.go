@@ -1,12 +1,43 @@
 package lilypad
 
 import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/imports"
 	"github.com/bacalhau-project/lilypad/pkg/jobcreator"
 	"github.com/bacalhau-project/lilypad/pkg/system"
 	"github.com/bacalhau-project/lilypad/pkg/web3"
 	"github.com/spf13/cobra"
 )
 
+// dealInventoryConcurrency bounds how many stateless jobs are in flight at
+// once when draining a --deal-inventory-file.
+const dealInventoryConcurrency = 32
+
+// jobOffersBatchSize caps how many rows of a --job-offers-batch-file are
+// sent to the solver per SubmitJobOffersBatch call.
+const jobOffersBatchSize = 1000
+
+type jobCreatorCliOptions struct {
+	jobcreator.JobCreatorOptions
+	Stateless          bool
+	DealInventoryFile  string
+	JobOffersBatchFile string
+	ReceiveResultsFile string
+	LocalImports       []string
+}
+
 func NewJobCreatorOptions() jobcreator.JobCreatorOptions {
 	return jobcreator.JobCreatorOptions{
 		Web3: getDefaultWeb3Options(),
@@ -14,7 +45,9 @@ func NewJobCreatorOptions() jobcreator.JobCreatorOptions {
 }
 
 func newJobCreatorCmd() *cobra.Command {
-	options := NewJobCreatorOptions()
+	options := jobCreatorCliOptions{
+		JobCreatorOptions: NewJobCreatorOptions(),
+	}
 
 	solverCmd := &cobra.Command{
 		Use:     "job-creator",
@@ -27,11 +60,35 @@ func newJobCreatorCmd() *cobra.Command {
 	}
 
 	addWeb3CliFlags(solverCmd, options.Web3)
+	solverCmd.PersistentFlags().BoolVar(
+		&options.Stateless, "stateless", false,
+		"Submit job specs straight to the solver/resource provider without persisting them locally or waiting for the FSM to advance.",
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.DealInventoryFile, "deal-inventory-file", "",
+		"A JSONL or CSV file of {module, inputs, resource_provider} rows to submit as stateless jobs (requires --stateless). For CSV, inputs is an optional column holding a JSON object.",
+	)
+	solverCmd.PersistentFlags().StringArrayVar(
+		&options.LocalImports, "local-import", nil,
+		"A local file or directory to pack into a CARv2 under its UnixFS root CID when the job creator starts. Job offers do not reference these CIDs. Can be repeated.",
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.SolverURL, "solver-url", "",
+		"Base URL of the solver's HTTP API, used by --job-offers-batch-file.",
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.JobOffersBatchFile, "job-offers-batch-file", "",
+		"A JSONL file of job offer rows to bulk-onboard into the solver via its batch endpoint (requires --solver-url).",
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.ReceiveResultsFile, "receive-results-file", "",
+		"A JSONL file of {deal_id, car_path, root_cid} rows to verify against their advertised root CID and submit to the solver (requires --solver-url).",
+	)
 
 	return solverCmd
 }
 
-func runJobCreator(cmd *cobra.Command, options jobcreator.JobCreatorOptions) error {
+func runJobCreator(cmd *cobra.Command, options jobCreatorCliOptions) error {
 	commandCtx := system.NewCommandContext(cmd)
 	defer commandCtx.Cleanup()
 
@@ -40,11 +97,27 @@ func runJobCreator(cmd *cobra.Command, options jobcreator.JobCreatorOptions) err
 		return err
 	}
 
-	solver, err := jobcreator.NewJobCreator(options, web3SDK)
+	for _, path := range options.LocalImports {
+		options.JobCreatorOptions.Inputs = append(options.JobCreatorOptions.Inputs, imports.LocalImport{Path: path})
+	}
+
+	solver, err := jobcreator.NewJobCreator(options.JobCreatorOptions, web3SDK)
 	if err != nil {
 		return err
 	}
 
+	if options.Stateless {
+		return runStatelessJobCreator(commandCtx.Ctx, solver, options.DealInventoryFile)
+	}
+
+	if options.JobOffersBatchFile != "" {
+		return runJobOffersBatchCreator(commandCtx.Ctx, solver, options.JobOffersBatchFile)
+	}
+
+	if options.ReceiveResultsFile != "" {
+		return runReceiveResults(commandCtx.Ctx, solver, options.ReceiveResultsFile)
+	}
+
 	err = solver.Start(commandCtx.Ctx, commandCtx.Cm)
 	if err != nil {
 		return err
@@ -53,3 +126,254 @@ func runJobCreator(cmd *cobra.Command, options jobcreator.JobCreatorOptions) err
 	<-commandCtx.Ctx.Done()
 	return nil
 }
+
+// dealInventoryRow is a single line of a --deal-inventory-file.
+type dealInventoryRow struct {
+	Module           string            `json:"module"`
+	Inputs           map[string]string `json:"inputs"`
+	ResourceProvider string            `json:"resource_provider"`
+}
+
+// runStatelessJobCreator streams --deal-inventory-file row by row through
+// jobcreator.RunStatelessJob with bounded parallelism, printing each
+// on-chain deal id as it lands.
+func runStatelessJobCreator(ctx context.Context, solver *jobcreator.JobCreator, dealInventoryFile string) error {
+	if dealInventoryFile == "" {
+		return fmt.Errorf("--stateless requires --deal-inventory-file")
+	}
+
+	sem := make(chan struct{}, dealInventoryConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	readErr := streamDealInventoryRows(dealInventoryFile, func(row dealInventoryRow) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dealID, err := solver.RunStatelessJob(ctx, jobcreator.JobSpec{
+				Module:           row.Module,
+				Inputs:           row.Inputs,
+				ResourceProvider: row.ResourceProvider,
+			})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			fmt.Println(dealID)
+		}()
+		return nil
+	})
+
+	wg.Wait()
+
+	if readErr != nil {
+		return readErr
+	}
+	return firstErr
+}
+
+func streamDealInventoryRows(filename string, fn func(dealInventoryRow) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(filename, ".csv") {
+		return streamDealInventoryRowsCSV(file, fn)
+	}
+	return streamDealInventoryRowsJSONL(file, fn)
+}
+
+func streamDealInventoryRowsJSONL(file *os.File, fn func(dealInventoryRow) error) error {
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var row dealInventoryRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return fmt.Errorf("error parsing deal inventory line: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func streamDealInventoryRowsCSV(file *os.File, fn func(dealInventoryRow) error) error {
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	moduleIdx, rpIdx, inputsIdx := -1, -1, -1
+	for i, column := range header {
+		switch column {
+		case "module":
+			moduleIdx = i
+		case "resource_provider":
+			rpIdx = i
+		case "inputs":
+			inputsIdx = i
+		}
+	}
+	if moduleIdx == -1 || rpIdx == -1 {
+		return fmt.Errorf("deal inventory CSV must have module and resource_provider columns")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := dealInventoryRow{
+			Module:           record[moduleIdx],
+			ResourceProvider: record[rpIdx],
+		}
+		if inputsIdx != -1 && record[inputsIdx] != "" {
+			if err := json.Unmarshal([]byte(record[inputsIdx]), &row.Inputs); err != nil {
+				return fmt.Errorf("error parsing deal inventory inputs column: %w", err)
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// runJobOffersBatchCreator streams --job-offers-batch-file row by row,
+// flushing a batch to the solver every jobOffersBatchSize rows instead of
+// buffering the whole file in memory first (the file can run to millions of
+// rows, same as --deal-inventory-file).
+func runJobOffersBatchCreator(ctx context.Context, solver *jobcreator.JobCreator, jobOffersBatchFile string) error {
+	batch := make([]data.JobOfferContainer, 0, jobOffersBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		stored, err := solver.SubmitJobOffersBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, jobOffer := range stored {
+			fmt.Println(jobOffer.ID)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	readErr := streamJobOffersBatchRows(jobOffersBatchFile, func(jobOffer data.JobOfferContainer) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		batch = append(batch, jobOffer)
+		if len(batch) < jobOffersBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if readErr != nil {
+		return readErr
+	}
+
+	return flush()
+}
+
+func streamJobOffersBatchRows(filename string, fn func(data.JobOfferContainer) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var jobOffer data.JobOfferContainer
+		if err := json.Unmarshal(scanner.Bytes(), &jobOffer); err != nil {
+			return fmt.Errorf("error parsing job offers batch line: %w", err)
+		}
+		if err := fn(jobOffer); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// receiveResultRow is a single line of a --receive-results-file.
+type receiveResultRow struct {
+	DealID  string `json:"deal_id"`
+	CARPath string `json:"car_path"`
+	RootCID string `json:"root_cid"`
+}
+
+// runReceiveResults streams --receive-results-file row by row, verifying
+// each result CAR against its advertised root CID and submitting it to the
+// solver via jobcreator.CompleteResult, the same verify-then-accept tail
+// Start would otherwise drive once its FSM loop exists.
+func runReceiveResults(ctx context.Context, solver *jobcreator.JobCreator, receiveResultsFile string) error {
+	return streamReceiveResultRows(receiveResultsFile, func(row receiveResultRow) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		rootCID, err := cid.Parse(row.RootCID)
+		if err != nil {
+			return fmt.Errorf("error parsing root CID for deal %s: %w", row.DealID, err)
+		}
+
+		result, err := solver.CompleteResult(ctx, row.DealID, row.CARPath, rootCID)
+		if err != nil {
+			return err
+		}
+		fmt.Println(result.DealID)
+		return nil
+	})
+}
+
+func streamReceiveResultRows(filename string, fn func(receiveResultRow) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var row receiveResultRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return fmt.Errorf("error parsing receive results line: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,257 @@
+package lilypad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/imports"
+	"github.com/bacalhau-project/lilypad/pkg/jobcreator"
+	"github.com/bacalhau-project/lilypad/pkg/solver"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store/memory"
+)
+
+func writeTempFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStreamDealInventoryRowsJSONL(t *testing.T) {
+	path := writeTempFile(t, "inventory.jsonl", `{"module":"m1","resource_provider":"rp1","inputs":{"a":"1"}}
+{"module":"m2","resource_provider":"rp2"}
+`)
+
+	var rows []dealInventoryRow
+	if err := streamDealInventoryRows(path, func(row dealInventoryRow) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Module != "m1" || rows[0].ResourceProvider != "rp1" || rows[0].Inputs["a"] != "1" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Module != "m2" || rows[1].ResourceProvider != "rp2" {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+}
+
+func TestStreamDealInventoryRowsCSVWithInputsColumn(t *testing.T) {
+	path := writeTempFile(t, "inventory.csv", `module,resource_provider,inputs
+m1,rp1,"{""a"":""1""}"
+m2,rp2,
+`)
+
+	var rows []dealInventoryRow
+	if err := streamDealInventoryRows(path, func(row dealInventoryRow) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Inputs["a"] != "1" {
+		t.Fatalf("expected inputs column to parse as JSON, got %+v", rows[0].Inputs)
+	}
+	if rows[1].Inputs != nil {
+		t.Fatalf("expected an empty inputs column to leave Inputs nil, got %+v", rows[1].Inputs)
+	}
+}
+
+func TestStreamDealInventoryRowsCSVRequiresModuleAndResourceProviderColumns(t *testing.T) {
+	path := writeTempFile(t, "inventory.csv", `module,inputs
+m1,
+`)
+
+	err := streamDealInventoryRows(path, func(dealInventoryRow) error { return nil })
+	if err == nil {
+		t.Fatal("expected an error when the resource_provider column is missing")
+	}
+}
+
+// TestStreamDealInventoryRowsStopsOnCallbackError confirms that once the
+// per-row callback returns an error (what happens once the ctx.Done() check
+// added for shutdown fires), the scan stops immediately instead of reading
+// the rest of the file.
+func TestStreamDealInventoryRowsStopsOnCallbackError(t *testing.T) {
+	path := writeTempFile(t, "inventory.jsonl", `{"module":"m1","resource_provider":"rp1"}
+{"module":"m2","resource_provider":"rp2"}
+{"module":"m3","resource_provider":"rp3"}
+`)
+
+	stopErr := errors.New("stop")
+	var seen int
+	err := streamDealInventoryRows(path, func(dealInventoryRow) error {
+		seen++
+		if seen == 1 {
+			return stopErr
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stopErr) {
+		t.Fatalf("expected the callback's error to propagate, got %v", err)
+	}
+	if seen != 1 {
+		t.Fatalf("expected the scan to stop after the first row, processed %d", seen)
+	}
+}
+
+func TestStreamJobOffersBatchRows(t *testing.T) {
+	path := writeTempFile(t, "batch.jsonl", `{"id":"offer1","job_creator":"jc1"}
+{"id":"offer2","job_creator":"jc1"}
+`)
+
+	var rows []data.JobOfferContainer
+	if err := streamJobOffersBatchRows(path, func(jobOffer data.JobOfferContainer) error {
+		rows = append(rows, jobOffer)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[0].ID != "offer1" || rows[1].ID != "offer2" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestStreamJobOffersBatchRowsRejectsMalformedLine(t *testing.T) {
+	path := writeTempFile(t, "batch.jsonl", "not json\n")
+
+	err := streamJobOffersBatchRows(path, func(data.JobOfferContainer) error { return nil })
+	if err == nil {
+		t.Fatal("expected a malformed line to return an error")
+	}
+}
+
+// TestRunJobOffersBatchCreatorStreamsAndSubmits is an end-to-end test for
+// --job-offers-batch-file: it drives runJobOffersBatchCreator against a real
+// solver and confirms every row lands. Its three rows fit in one batch of
+// jobOffersBatchSize, so flushing more than one batch is not exercised.
+func TestRunJobOffersBatchCreatorStreamsAndSubmits(t *testing.T) {
+	path := writeTempFile(t, "batch.jsonl", `{"id":"offer1","job_creator":"jc1"}
+{"id":"offer2","job_creator":"jc1"}
+{"id":"offer3","job_creator":"jc1"}
+`)
+
+	solverStore, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer solverStore.Close()
+
+	s, err := solver.NewSolver(solver.SolverOptions{}, solverStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	jobCreator, err := jobcreator.NewJobCreator(jobcreator.JobCreatorOptions{SolverURL: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runJobOffersBatchCreator(context.Background(), jobCreator, path); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"offer1", "offer2", "offer3"} {
+		jobOffer, err := solverStore.GetJobOffer(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jobOffer == nil {
+			t.Fatalf("expected %s to have been submitted to the solver", id)
+		}
+	}
+}
+
+// TestStreamReceiveResultRows exercises the --receive-results-file row
+// parser in isolation from the CAR verification/submission it drives.
+func TestStreamReceiveResultRows(t *testing.T) {
+	path := writeTempFile(t, "results.jsonl", `{"deal_id":"deal1","car_path":"/tmp/deal1.car","root_cid":"bafy1"}
+{"deal_id":"deal2","car_path":"/tmp/deal2.car","root_cid":"bafy2"}
+`)
+
+	var rows []receiveResultRow
+	if err := streamReceiveResultRows(path, func(row receiveResultRow) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[0].DealID != "deal1" || rows[1].DealID != "deal2" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+}
+
+// TestRunReceiveResultsVerifiesAndSubmits is an end-to-end regression test
+// for --receive-results-file: it packs a real result CAR, runs
+// runReceiveResults against a real solver, and confirms the result lands.
+func TestRunReceiveResultsVerifiesAndSubmits(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "output.txt"), []byte("a sealed job result"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	importsManager, err := imports.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := importsManager.Add(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer importsManager.Remove(packed.RootCID.String())
+
+	path := writeTempFile(t, "results.jsonl", fmt.Sprintf(`{"deal_id":"deal1","car_path":%q,"root_cid":%q}
+`, packed.CARPath(), packed.RootCID.String()))
+
+	solverStore, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer solverStore.Close()
+
+	s, err := solver.NewSolver(solver.SolverOptions{}, solverStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	jobCreator, err := jobcreator.NewJobCreator(jobcreator.JobCreatorOptions{SolverURL: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runReceiveResults(context.Background(), jobCreator, path); err != nil {
+		t.Fatal(err)
+	}
+
+	storedResult, err := solverStore.GetResult(context.Background(), "deal1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedResult == nil {
+		t.Fatal("expected the result to have been submitted to the solver")
+	}
+}
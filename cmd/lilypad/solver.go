@@ -0,0 +1,112 @@
+package lilypad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bacalhau-project/lilypad/pkg/solver"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store"
+	memorystore "github.com/bacalhau-project/lilypad/pkg/solver/store/memory"
+	sqlitestore "github.com/bacalhau-project/lilypad/pkg/solver/store/sqlite"
+	"github.com/bacalhau-project/lilypad/pkg/system"
+	"github.com/bacalhau-project/lilypad/pkg/web3"
+	"github.com/spf13/cobra"
+)
+
+// solverStoreCompactionInterval is how often the JSONL memory backend folds
+// its in-memory state into a snapshot and truncates its log.
+const solverStoreCompactionInterval = 10 * time.Minute
+
+type solverCliOptions struct {
+	solver.SolverOptions
+	StoreBackend string
+	SQLitePath   string
+}
+
+// defaultSolverListenAddress is the host:port the solver's HTTP API listens
+// on when --listen-address isn't passed.
+const defaultSolverListenAddress = ":8080"
+
+func NewSolverOptions() solver.SolverOptions {
+	return solver.SolverOptions{
+		Web3:          getDefaultWeb3Options(),
+		ListenAddress: defaultSolverListenAddress,
+	}
+}
+
+func newSolverCmd() *cobra.Command {
+	options := solverCliOptions{
+		SolverOptions: NewSolverOptions(),
+		StoreBackend:  string(store.BackendKindMemory),
+		SQLitePath:    "/var/tmp/lilypad_solver.sqlite3",
+	}
+
+	solverCmd := &cobra.Command{
+		Use:     "solver",
+		Short:   "Start the lilypad solver service.",
+		Long:    "Start the lilypad solver service.",
+		Example: "",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSolver(cmd, options)
+		},
+	}
+
+	addWeb3CliFlags(solverCmd, options.Web3)
+	solverCmd.PersistentFlags().StringVar(
+		&options.StoreBackend, "store-backend", options.StoreBackend,
+		fmt.Sprintf("Solver store backend to use: %q or %q.", store.BackendKindMemory, store.BackendKindSQLite),
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.SQLitePath, "sqlite-path", options.SQLitePath,
+		"Path to the SQLite database file, when --store-backend=sqlite.",
+	)
+	solverCmd.PersistentFlags().StringVar(
+		&options.ListenAddress, "listen-address", options.ListenAddress,
+		"Host:port the solver's HTTP API (job offers batch, results, leaderboard) listens on.",
+	)
+
+	return solverCmd
+}
+
+func newSolverStoreBackend(options solverCliOptions) (store.Backend, error) {
+	switch store.BackendKind(options.StoreBackend) {
+	case store.BackendKindMemory:
+		return memorystore.NewSolverStoreMemory()
+	case store.BackendKindSQLite:
+		return sqlitestore.NewSolverStoreSQLite(options.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown store backend: %s", options.StoreBackend)
+	}
+}
+
+func runSolver(cmd *cobra.Command, options solverCliOptions) error {
+	commandCtx := system.NewCommandContext(cmd)
+	defer commandCtx.Cleanup()
+
+	web3SDK, err := web3.NewContractSDK(options.Web3)
+	if err != nil {
+		return err
+	}
+
+	solverStore, err := newSolverStoreBackend(options)
+	if err != nil {
+		return err
+	}
+
+	if memoryStore, ok := solverStore.(*memorystore.SolverStoreMemory); ok {
+		memoryStore.StartCompaction(commandCtx.Ctx, solverStoreCompactionInterval)
+	}
+
+	s, err := solver.NewSolver(options.SolverOptions, solverStore, web3SDK)
+	if err != nil {
+		return err
+	}
+
+	err = s.Start(commandCtx.Ctx, commandCtx.Cm)
+	if err != nil {
+		return err
+	}
+
+	<-commandCtx.Ctx.Done()
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package data holds the wire/storage types shared between the solver,
+// job-creator and resource-provider services.
+package data
+
+import (
+	"fmt"
+)
+
+type JobOfferContainer struct {
+	ID         string `json:"id"`
+	JobCreator string `json:"job_creator"`
+	DealID     string `json:"deal_id"`
+	State      uint8  `json:"state"`
+}
+
+type ResourceOfferContainer struct {
+	ID               string `json:"id"`
+	ResourceProvider string `json:"resource_provider"`
+	DealID           string `json:"deal_id"`
+	State            uint8  `json:"state"`
+}
+
+type DealTransactionsResourceProvider struct {
+	Agree                string `json:"agree"`
+	AddResult            string `json:"add_result"`
+	TimeoutAgree         string `json:"timeout_agree"`
+	TimeoutJudgeResult   string `json:"timeout_judge_result"`
+	TimeoutMediateResult string `json:"timeout_mediate_result"`
+}
+
+type DealTransactionsJobCreator struct {
+	Agree                string `json:"agree"`
+	AcceptResult         string `json:"accept_result"`
+	CheckResult          string `json:"check_result"`
+	TimeoutAgree         string `json:"timeout_agree"`
+	TimeoutSubmitResult  string `json:"timeout_submit_result"`
+	TimeoutMediateResult string `json:"timeout_mediate_result"`
+}
+
+type DealTransactionsMediator struct {
+	MediationAcceptResult string `json:"mediation_accept_result"`
+	MediationRejectResult string `json:"mediation_reject_result"`
+}
+
+type DealTransactions struct {
+	ResourceProvider DealTransactionsResourceProvider `json:"resource_provider"`
+	JobCreator       DealTransactionsJobCreator       `json:"job_creator"`
+	Mediator         DealTransactionsMediator         `json:"mediator"`
+}
+
+type DealContainer struct {
+	ID               string           `json:"id"`
+	JobCreator       string           `json:"job_creator"`
+	ResourceProvider string           `json:"resource_provider"`
+	Mediator         string           `json:"mediator"`
+	State            uint8            `json:"state"`
+	Transactions     DealTransactions `json:"transactions"`
+}
+
+type Result struct {
+	DealID string `json:"deal_id"`
+	// RootCID is the UnixFS root CID of the result CAR, so third parties
+	// can fetch and verify the sealed result independently of the
+	// job-creator that requested it.
+	RootCID string `json:"root_cid"`
+	CARSize int64  `json:"car_size"`
+}
+
+type MatchDecision struct {
+	ResourceOffer string `json:"resource_offer"`
+	JobOffer      string `json:"job_offer"`
+	Deal          string `json:"deal"`
+	Result        bool   `json:"result"`
+}
+
+type LeaderboardEntry struct {
+	ResourceProvider string `json:"resource_provider"`
+	JobCount         int    `json:"job_count"`
+	FailureCount     int    `json:"failure_count"`
+	TimeoutCount     int    `json:"timeout_count"`
+}
+
+// SuccessRate is the share of this resource provider's concluded deals
+// (successful, rejected or timed out) that ended successfully, or 0 if it
+// has no concluded deals yet.
+func (e LeaderboardEntry) SuccessRate() float64 {
+	concluded := e.JobCount + e.FailureCount + e.TimeoutCount
+	if concluded == 0 {
+		return 0
+	}
+	return float64(e.JobCount) / float64(concluded)
+}
+
+// LeaderboardComparator orders two leaderboard entries for sorting: negative
+// if a ranks above b, positive if below, zero if tied. This is the same
+// contract as a standard library comparison function, so a comparator can
+// be passed to slices.SortFunc as is; sort.Slice needs it wrapped in a less
+// function.
+type LeaderboardComparator func(a, b LeaderboardEntry) int
+
+// ByJobCount ranks resource providers by successful deal count, highest
+// first.
+func ByJobCount(a, b LeaderboardEntry) int {
+	return b.JobCount - a.JobCount
+}
+
+// BySuccessRate ranks resource providers by the share of concluded deals
+// that ended successfully, highest first.
+func BySuccessRate(a, b LeaderboardEntry) int {
+	switch {
+	case a.SuccessRate() > b.SuccessRate():
+		return -1
+	case a.SuccessRate() < b.SuccessRate():
+		return 1
+	default:
+		return 0
+	}
+}
+
+// leaderboardComparators is the registry of built-in comparators a caller
+// can select by name via store.GetLeaderboardQuery.SortBy. Comparators for
+// latency, collateral and recency were dropped here: deals don't carry
+// timestamps or collateral amounts in this store, so those sort keys
+// compared every entry as tied and produced an arbitrary order. Re-add them
+// once deals track that data.
+var leaderboardComparators = map[string]LeaderboardComparator{
+	"job_count":    ByJobCount,
+	"success_rate": BySuccessRate,
+}
+
+// GetLeaderboardComparator looks up a built-in comparator by name, defaulting
+// to ByJobCount when name is empty.
+func GetLeaderboardComparator(name string) (LeaderboardComparator, error) {
+	if name == "" {
+		return ByJobCount, nil
+	}
+	comparator, ok := leaderboardComparators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown leaderboard sort: %s", name)
+	}
+	return comparator, nil
+}
+
+// Agreement states, shared by job offers, resource offers and deals.
+const (
+	AgreementStateNone uint8 = iota
+	AgreementStateAgreed
+	AgreementStateResultsSubmitted
+	AgreementStateResultsAccepted
+	AgreementStateResultsRejected
+	AgreementStateMediationAccepted
+	AgreementStateMediationRejected
+	AgreementStateTimeout
+)
+
+var agreementStateNames = map[string]uint8{
+	"none":               AgreementStateNone,
+	"agreed":             AgreementStateAgreed,
+	"results_submitted":  AgreementStateResultsSubmitted,
+	"results_accepted":   AgreementStateResultsAccepted,
+	"results_rejected":   AgreementStateResultsRejected,
+	"mediation_accepted": AgreementStateMediationAccepted,
+	"mediation_rejected": AgreementStateMediationRejected,
+	"timeout":            AgreementStateTimeout,
+}
+
+func GetAgreementState(name string) (uint8, error) {
+	state, ok := agreementStateNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown agreement state: %s", name)
+	}
+	return state, nil
+}
+
+func IsActiveAgreementState(state uint8) bool {
+	return state == AgreementStateNone || state == AgreementStateAgreed
+}
+
+func IsSuccessfulAgreementState(state uint8) bool {
+	return state == AgreementStateResultsAccepted || state == AgreementStateMediationAccepted
+}
@@ -0,0 +1,51 @@
+package data
+
+import "testing"
+
+func TestByJobCountRanksHighestFirst(t *testing.T) {
+	a := LeaderboardEntry{ResourceProvider: "a", JobCount: 1}
+	b := LeaderboardEntry{ResourceProvider: "b", JobCount: 2}
+	if ByJobCount(a, b) <= 0 {
+		t.Fatalf("expected b to rank above a, got ByJobCount(a, b) = %d", ByJobCount(a, b))
+	}
+	if ByJobCount(b, a) >= 0 {
+		t.Fatalf("expected a to rank below b, got ByJobCount(b, a) = %d", ByJobCount(b, a))
+	}
+}
+
+func TestBySuccessRateRanksHighestFirst(t *testing.T) {
+	a := LeaderboardEntry{ResourceProvider: "a", JobCount: 1, FailureCount: 1} // 50%
+	b := LeaderboardEntry{ResourceProvider: "b", JobCount: 9, FailureCount: 1} // 90%
+	if BySuccessRate(a, b) <= 0 {
+		t.Fatalf("expected b to rank above a, got BySuccessRate(a, b) = %d", BySuccessRate(a, b))
+	}
+}
+
+func TestGetLeaderboardComparatorDefaultsToJobCount(t *testing.T) {
+	comparator, err := GetLeaderboardComparator("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a := LeaderboardEntry{JobCount: 1}
+	b := LeaderboardEntry{JobCount: 2}
+	if comparator(a, b) != ByJobCount(a, b) {
+		t.Fatalf("expected default comparator to behave like ByJobCount")
+	}
+}
+
+func TestGetLeaderboardComparatorUnknownNameErrors(t *testing.T) {
+	if _, err := GetLeaderboardComparator("not_a_real_sort"); err == nil {
+		t.Fatal("expected an error for an unknown comparator name")
+	}
+}
+
+// These sort keys were removed from the registry because nothing populates
+// the underlying fields, which made them silently arbitrary; make sure they
+// stay gone rather than quietly becoming selectable again.
+func TestGetLeaderboardComparatorDroppedKeysStayUnregistered(t *testing.T) {
+	for _, name := range []string{"avg_latency", "total_collateral", "recent_activity"} {
+		if _, err := GetLeaderboardComparator(name); err == nil {
+			t.Errorf("expected %q to be unregistered, got no error", name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Package imports packs local files and directories into content-addressed
+// CARv2 archives so job offers and results can reference a UnixFS root CID
+// instead of an opaque IPFS/URL string, mirroring Lotus's
+// node/repo/imports.Manager.
+package imports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-unixfsnode/data/builder"
+	carv2 "github.com/ipld/go-car/v2"
+	carblockstore "github.com/ipld/go-car/v2/blockstore"
+	ipld "github.com/ipld/go-ipld-prime"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/bsadapter"
+	"github.com/ipld/go-ipld-prime/traversal"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+// importsDir holds the CARv2 files this process has packed, keyed by their
+// UnixFS root CID.
+const importsDir = "/var/tmp/lilypad_imports"
+
+// LocalImport is a local file or directory that has been packed into a
+// CARv2 and is ready to be referenced in a job offer by its root CID.
+type LocalImport struct {
+	Path    string
+	RootCID cid.Cid
+	CARSize int64
+}
+
+// CARPath is where the packed archive for this import lives on disk.
+func (l LocalImport) CARPath() string {
+	return filepath.Join(importsDir, l.RootCID.String()+".car")
+}
+
+type Manager struct {
+	mutex   sync.Mutex
+	imports map[string]*LocalImport
+}
+
+func NewManager() (*Manager, error) {
+	if err := os.MkdirAll(importsDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating imports directory: %w", err)
+	}
+	return &Manager{
+		imports: map[string]*LocalImport{},
+	}, nil
+}
+
+// Add packs path (a file or directory) into a CARv2 with a UnixFS DAG,
+// computes its root CID, and registers it so it can be streamed to a
+// resource provider by CID.
+func (m *Manager) Add(ctx context.Context, path string) (*LocalImport, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rootCID, carPath, err := packToCAR(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("error packing import %s: %w", path, err)
+	}
+
+	info, err := os.Stat(carPath)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &LocalImport{
+		Path:    path,
+		RootCID: rootCID,
+		CARSize: info.Size(),
+	}
+	m.imports[rootCID.String()] = imp
+
+	return imp, nil
+}
+
+// Get looks up a previously registered import by its root CID.
+func (m *Manager) Get(rootCID string) (*LocalImport, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	imp, ok := m.imports[rootCID]
+	return imp, ok
+}
+
+// Remove deletes the packed CAR for rootCID and forgets it.
+func (m *Manager) Remove(rootCID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	imp, ok := m.imports[rootCID]
+	if !ok {
+		return nil
+	}
+	delete(m.imports, rootCID)
+	return os.Remove(imp.CARPath())
+}
+
+// packToCAR walks path, builds a UnixFS DAG of its contents into a CARv2
+// blockstore and finalizes it under importsDir, returning the DAG's root CID
+// and the archive's path on disk. The root CID isn't known until the DAG is
+// built, so the blockstore is opened with no roots and patched with the
+// real one afterwards via carv2.ReplaceRootsInFile.
+func packToCAR(ctx context.Context, path string) (cid.Cid, string, error) {
+	if err := os.MkdirAll(importsDir, 0755); err != nil {
+		return cid.Undef, "", err
+	}
+
+	tmpCAR := filepath.Join(importsDir, fmt.Sprintf("tmp-%d.car", os.Getpid()))
+
+	rwbs, err := carblockstore.OpenReadWrite(tmpCAR, []cid.Cid{})
+	if err != nil {
+		return cid.Undef, "", fmt.Errorf("error opening CARv2 blockstore: %w", err)
+	}
+
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.TrustedStorage = true
+	lsys.SetReadStorage(&bsadapter.Adapter{Wrapped: rwbs})
+	lsys.SetWriteStorage(&bsadapter.Adapter{Wrapped: rwbs})
+
+	rootLink, _, err := builder.BuildUnixFSRecursive(path, &lsys)
+	if err != nil {
+		_ = rwbs.Finalize()
+		return cid.Undef, "", fmt.Errorf("error building UnixFS DAG: %w", err)
+	}
+
+	rootCID := rootLink.(cidlink.Link).Cid
+
+	if err := rwbs.Finalize(); err != nil {
+		return cid.Undef, "", fmt.Errorf("error finalizing CARv2 blockstore: %w", err)
+	}
+	if err := carv2.ReplaceRootsInFile(tmpCAR, []cid.Cid{rootCID}); err != nil {
+		return cid.Undef, "", fmt.Errorf("error setting CARv2 root: %w", err)
+	}
+
+	finalCAR := filepath.Join(importsDir, rootCID.String()+".car")
+	if err := os.Rename(tmpCAR, finalCAR); err != nil {
+		return cid.Undef, "", err
+	}
+
+	return rootCID, finalCAR, nil
+}
+
+// VerifyCAR checks that the CARv2 at carPath actually contains the DAG
+// rooted at expectedRootCID, used on the job-creator side to verify a
+// result archive before calling AddResult. It's not enough to check the
+// CAR's self-declared header roots, since those are just metadata a
+// corrupted or malicious peer could set to anything; every block visited is
+// fetched and its content is hashed against its own CID (the LinkSystem's
+// untrusted-storage default) as the DAG is walked from the root.
+func VerifyCAR(ctx context.Context, carPath string, expectedRootCID cid.Cid) error {
+	reader, err := carblockstore.OpenReadOnly(carPath)
+	if err != nil {
+		return fmt.Errorf("error opening result CAR: %w", err)
+	}
+	defer reader.Close()
+
+	roots, err := reader.Roots()
+	if err != nil {
+		return fmt.Errorf("error reading result CAR roots: %w", err)
+	}
+	found := false
+	for _, root := range roots {
+		if root.Equals(expectedRootCID) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("result CAR root does not match advertised CID %s", expectedRootCID)
+	}
+
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(&bsadapter.Adapter{Wrapped: reader})
+
+	rootLink := cidlink.Link{Cid: expectedRootCID}
+	rootNode, err := lsys.Load(ipld.LinkContext{Ctx: ctx}, rootLink, basicnode.Prototype.Any)
+	if err != nil {
+		return fmt.Errorf("error loading/verifying result CAR root block: %w", err)
+	}
+
+	progress := traversal.Progress{
+		Cfg: &traversal.Config{
+			Ctx:                            ctx,
+			LinkSystem:                     lsys,
+			LinkTargetNodePrototypeChooser: func(ipld.Link, ipld.LinkContext) (ipld.NodePrototype, error) { return basicnode.Prototype.Any, nil },
+		},
+	}
+	if err := progress.WalkMatching(rootNode, selectorparse.CommonSelector_ExploreAllRecursively, func(traversal.Progress, ipld.Node) error {
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error verifying result CAR DAG completeness: %w", err)
+	}
+
+	return nil
+}
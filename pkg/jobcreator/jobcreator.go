@@ -0,0 +1,226 @@
+package jobcreator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/imports"
+	"github.com/bacalhau-project/lilypad/pkg/system"
+	"github.com/bacalhau-project/lilypad/pkg/web3"
+)
+
+// jobOffersBatchPath is the solver endpoint SubmitJobOffersBatch posts to.
+const jobOffersBatchPath = "/api/v1/job_offers/batch"
+
+// resultsPath is the solver endpoint SubmitResult posts to.
+const resultsPath = "/api/v1/results"
+
+// JobCreatorOptions configures the job-creator service.
+type JobCreatorOptions struct {
+	Web3 web3.Web3Options
+	// Inputs are local files/directories that NewJobCreator packs into
+	// CARv2s; their root CIDs are available from RegisteredInputs. Job
+	// offers submitted by this package do not carry those CIDs.
+	Inputs []imports.LocalImport
+	// SolverURL is the base URL of the solver's HTTP API, used by
+	// SubmitJobOffersBatch.
+	SolverURL string
+}
+
+// JobSpec describes a single job to submit directly via RunStatelessJob.
+type JobSpec struct {
+	Module           string
+	Inputs           map[string]string
+	ResourceProvider string
+}
+
+type JobCreator struct {
+	options        JobCreatorOptions
+	web3SDK        *web3.ContractSDK
+	importsManager *imports.Manager
+	// registeredInputs is options.Inputs as importsManager.Add resolved
+	// them (RootCID and CARSize filled in).
+	registeredInputs []imports.LocalImport
+}
+
+func NewJobCreator(options JobCreatorOptions, web3SDK *web3.ContractSDK) (*JobCreator, error) {
+	importsManager, err := imports.NewManager()
+	if err != nil {
+		return nil, err
+	}
+
+	registeredInputs := make([]imports.LocalImport, 0, len(options.Inputs))
+	for _, input := range options.Inputs {
+		registered, err := importsManager.Add(context.Background(), input.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error registering import %s: %w", input.Path, err)
+		}
+		registeredInputs = append(registeredInputs, *registered)
+	}
+
+	return &JobCreator{
+		options:          options,
+		web3SDK:          web3SDK,
+		importsManager:   importsManager,
+		registeredInputs: registeredInputs,
+	}, nil
+}
+
+// RegisteredInputs returns options.Inputs as packed at construction time,
+// with RootCID and CARSize filled in.
+func (jobCreator *JobCreator) RegisteredInputs() []imports.LocalImport {
+	return jobCreator.registeredInputs
+}
+
+// Start is meant to run the normal job-creator service: watching the solver
+// for matches against the job offers it has submitted and driving each one
+// through the deal FSM until it is accepted or times out, agreeing the
+// match and streaming any registered imports to the resource provider over
+// a CAR-based data-transfer channel. Neither the match-watching loop nor
+// that transfer channel exist yet, so Start errors rather than silently
+// returning as a no-op. The tail end of the workflow it would drive -
+// verifying the result CAR the resource provider returns and submitting it
+// to the solver - is wired up and usable on its own via CompleteResult; use
+// that, RunStatelessJob or SubmitJobOffersBatch until Start itself is
+// implemented.
+func (jobCreator *JobCreator) Start(ctx context.Context, cm *system.CleanupManager) error {
+	return fmt.Errorf("job-creator FSM loop is not implemented yet; use --stateless, --job-offers-batch-file or --receive-results-file")
+}
+
+// ReceiveResult verifies a result CAR streamed back by the resource
+// provider against the root CID it advertised for dealID, and returns the
+// data.Result record ready to be submitted to the solver's AddResult. It
+// lets third parties fetch and check the sealed result independently of
+// the job-creator that requested it, instead of trusting an opaque
+// IPFS/URL string.
+func (jobCreator *JobCreator) ReceiveResult(ctx context.Context, dealID string, carPath string, expectedRootCID cid.Cid) (*data.Result, error) {
+	if err := imports.VerifyCAR(ctx, carPath, expectedRootCID); err != nil {
+		return nil, fmt.Errorf("error verifying result for deal %s: %w", dealID, err)
+	}
+
+	info, err := os.Stat(carPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.Result{
+		DealID:  dealID,
+		RootCID: expectedRootCID.String(),
+		CARSize: info.Size(),
+	}, nil
+}
+
+// SubmitResult posts result to the solver's AddResult endpoint.
+func (jobCreator *JobCreator) SubmitResult(ctx context.Context, result data.Result) (*data.Result, error) {
+	if jobCreator.options.SolverURL == "" {
+		return nil, fmt.Errorf("submitting a result requires a solver URL")
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, jobCreator.options.SolverURL+resultsPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error submitting result: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("solver returned %s: %s", resp.Status, string(respBody))
+	}
+
+	var stored data.Result
+	if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// CompleteResult verifies a result CAR against its advertised root CID (via
+// ReceiveResult) and, once that checks out, submits it to the solver (via
+// SubmitResult). It drives the verify-then-accept tail of the deal workflow
+// end to end for a caller that already has a result CAR in hand, independent
+// of the rest of Start's unimplemented FSM loop.
+func (jobCreator *JobCreator) CompleteResult(ctx context.Context, dealID string, carPath string, expectedRootCID cid.Cid) (*data.Result, error) {
+	result, err := jobCreator.ReceiveResult(ctx, dealID, carPath, expectedRootCID)
+	if err != nil {
+		return nil, err
+	}
+	return jobCreator.SubmitResult(ctx, *result)
+}
+
+// RunStatelessJob submits spec directly to the solver/resource provider and
+// returns the on-chain deal id once the deal has been created, without
+// persisting anything locally and without waiting for the FSM to advance
+// through agree/result/accept. It's intended for batch pipelines submitting
+// large numbers of prearranged jobs against known resource providers, where
+// a JSONL row per job (or a long-lived process per job) is not wanted.
+func (jobCreator *JobCreator) RunStatelessJob(ctx context.Context, spec JobSpec) (string, error) {
+	if spec.ResourceProvider == "" {
+		return "", fmt.Errorf("stateless job requires a resource provider")
+	}
+
+	dealID, err := jobCreator.web3SDK.SubmitPrearrangedDeal(ctx, spec.Module, spec.Inputs, spec.ResourceProvider)
+	if err != nil {
+		return "", fmt.Errorf("error submitting stateless job: %w", err)
+	}
+
+	return dealID, nil
+}
+
+// SubmitJobOffersBatch posts jobOffers to the solver's batch endpoint in a
+// single HTTP request, landing under one store.Tx instead of one AddJobOffer
+// call per offer. It returns the offers as the solver recorded them.
+func (jobCreator *JobCreator) SubmitJobOffersBatch(ctx context.Context, jobOffers []data.JobOfferContainer) ([]data.JobOfferContainer, error) {
+	if jobCreator.options.SolverURL == "" {
+		return nil, fmt.Errorf("job offers batch submit requires a solver URL")
+	}
+	if len(jobOffers) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(jobOffers)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, jobCreator.options.SolverURL+jobOffersBatchPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error submitting job offers batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("solver returned %s: %s", resp.Status, string(respBody))
+	}
+
+	var stored []data.JobOfferContainer
+	if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
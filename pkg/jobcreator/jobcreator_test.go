@@ -0,0 +1,152 @@
+package jobcreator
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/imports"
+	"github.com/bacalhau-project/lilypad/pkg/solver"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store/memory"
+)
+
+// TestSubmitJobOffersBatchRoundTripsThroughARealSolver is an end-to-end
+// regression test for SubmitJobOffersBatch positing to a solver with
+// nothing listening on the other end: it drives the real
+// POST /api/v1/job_offers/batch route a running solver mounts, instead of
+// calling solver.AddJobOffersBatchHandler directly.
+func TestSubmitJobOffersBatchRoundTripsThroughARealSolver(t *testing.T) {
+	solverStore, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer solverStore.Close()
+
+	s, err := solver.NewSolver(solver.SolverOptions{}, solverStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	jobCreator, err := NewJobCreator(JobCreatorOptions{SolverURL: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := jobCreator.SubmitJobOffersBatch(context.Background(), []data.JobOfferContainer{
+		{ID: "offer1", JobCreator: "jc1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].ID != "offer1" {
+		t.Fatalf("expected the solver to echo back the stored job offer, got %+v", stored)
+	}
+
+	jobOffer, err := solverStore.GetJobOffer(context.Background(), "offer1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobOffer == nil {
+		t.Fatal("expected the batch submission to have actually landed in the solver's store")
+	}
+}
+
+// TestCompleteResultVerifiesAndSubmitsThroughARealSolver is an end-to-end
+// regression test for the chunk0-3 wiring: it packs a real result CAR,
+// drives CompleteResult (ReceiveResult + SubmitResult) against a real
+// solver's POST /api/v1/results route, and confirms the result lands.
+func TestCompleteResultVerifiesAndSubmitsThroughARealSolver(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "output.txt"), []byte("a sealed job result"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	importsManager, err := imports.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := importsManager.Add(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer importsManager.Remove(result.RootCID.String())
+
+	solverStore, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer solverStore.Close()
+
+	s, err := solver.NewSolver(solver.SolverOptions{}, solverStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	jobCreator, err := NewJobCreator(JobCreatorOptions{SolverURL: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := jobCreator.CompleteResult(context.Background(), "deal1", result.CARPath(), result.RootCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DealID != "deal1" || stored.RootCID != result.RootCID.String() {
+		t.Fatalf("unexpected stored result: %+v", stored)
+	}
+
+	storedResult, err := solverStore.GetResult(context.Background(), "deal1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedResult == nil {
+		t.Fatal("expected CompleteResult to have actually landed in the solver's store")
+	}
+}
+
+// TestCompleteResultRejectsTamperedCAR confirms CompleteResult never reaches
+// the solver if the result CAR doesn't verify against the advertised root
+// CID: ReceiveResult's check must short-circuit SubmitResult.
+func TestCompleteResultRejectsTamperedCAR(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "output.txt"), []byte("a sealed job result"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	importsManager, err := imports.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := importsManager.Add(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer importsManager.Remove(result.RootCID.String())
+
+	tampered := filepath.Join(t.TempDir(), "tampered.car")
+	carBytes, err := os.ReadFile(result.CARPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	carBytes[len(carBytes)-1] ^= 0xFF
+	if err := os.WriteFile(tampered, carBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jobCreator, err := NewJobCreator(JobCreatorOptions{SolverURL: "http://localhost:0"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := jobCreator.CompleteResult(context.Background(), "deal1", tampered, result.RootCID); err == nil {
+		t.Fatal("expected CompleteResult to reject a tampered CAR before ever contacting the solver")
+	}
+}
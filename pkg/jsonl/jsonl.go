@@ -0,0 +1,71 @@
+// Package jsonl writes newline-delimited JSON records to an append-only log.
+package jsonl
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+type Writer interface {
+	Write(record any) error
+	// WriteBatch appends every record in one lock acquisition and one
+	// underlying Write call.
+	WriteBatch(records []any) error
+}
+
+type writer struct {
+	out   io.Writer
+	mutex sync.Mutex
+}
+
+func NewWriter(out io.Writer) Writer {
+	return &writer{out: out}
+}
+
+func (w *writer) Write(record any) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	_, err = w.out.Write(line)
+	return err
+}
+
+func (w *writer) WriteBatch(records []any) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	lines := make([]byte, 0, 256*len(records))
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.out.Write(lines)
+	return err
+}
+
+// Close closes the underlying output if it is an io.Closer.
+func (w *writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if closer, ok := w.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
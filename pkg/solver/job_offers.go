@@ -0,0 +1,31 @@
+package solver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+)
+
+// AddJobOffersBatchHandler serves POST /api/v1/job_offers/batch: the request
+// body is a JSON array of data.JobOfferContainer, committed to the store
+// under a single store.Tx call. It's mounted by Solver.mux, which Start
+// serves on SolverOptions.ListenAddress.
+func (solver *Solver) AddJobOffersBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var jobOffers []data.JobOfferContainer
+	if err := json.NewDecoder(r.Body).Decode(&jobOffers); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stored, err := solver.store.AddJobOffersBatch(r.Context(), jobOffers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stored); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store"
+)
+
+// defaultLeaderboardLimit caps how many entries GetLeaderboardHandler
+// returns per page when the caller doesn't pass ?limit.
+const defaultLeaderboardLimit = 100
+
+// GetLeaderboardHandler serves the leaderboard over HTTP: sort_by,
+// resource_provider, limit and offset query parameters become a
+// store.GetLeaderboardQuery, and the ranked, paginated result is written
+// back as JSON. It's mounted by Solver.mux, which Start serves on
+// SolverOptions.ListenAddress.
+func (solver *Solver) GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLeaderboardLimit
+	limitSet := false
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid limit: must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+		limitSet = true
+	}
+
+	offset := 0
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid offset: must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	// An explicit ?limit=0 asks for zero rows; GetLeaderboardData instead
+	// treats a zero Limit as "unlimited", so that request has to be
+	// special-cased here rather than passed through.
+	if limitSet && limit == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode([]data.LeaderboardEntry{}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	query := store.GetLeaderboardQuery{
+		SortBy:           r.URL.Query().Get("sort_by"),
+		ResourceProvider: r.URL.Query().Get("resource_provider"),
+		// Fetch enough ranked entries to cover this page; offset is applied
+		// below since GetLeaderboardData itself has no concept of paging
+		// from the middle of the ranking.
+		Limit: offset + limit,
+	}
+
+	entries, err := solver.store.GetLeaderboardData(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if offset > len(entries) {
+		offset = len(entries)
+	}
+	entries = entries[offset:]
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,67 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+)
+
+func addTestDeal(t *testing.T, s *Solver, resourceProvider string, state uint8) {
+	t.Helper()
+	_, err := s.store.AddDeal(context.Background(), data.DealContainer{
+		ID:               resourceProvider + "-deal",
+		ResourceProvider: resourceProvider,
+		State:            state,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Regression test: an explicit ?limit=0 used to be indistinguishable from
+// "no limit passed" by the time it reached GetLeaderboardData (which treats
+// a zero Limit as unlimited), so it returned the whole leaderboard instead
+// of zero rows.
+func TestGetLeaderboardHandlerExplicitZeroLimitReturnsNoRows(t *testing.T) {
+	s := newTestSolver(t)
+	addTestDeal(t, s, "rp1", data.AgreementStateResultsAccepted)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/leaderboard?limit=0", nil)
+	w := httptest.NewRecorder()
+	s.GetLeaderboardHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var entries []data.LeaderboardEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected ?limit=0 to return no entries, got %+v", entries)
+	}
+}
+
+func TestGetLeaderboardHandlerDefaultLimitReturnsEntries(t *testing.T) {
+	s := newTestSolver(t)
+	addTestDeal(t, s, "rp1", data.AgreementStateResultsAccepted)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/leaderboard", nil)
+	w := httptest.NewRecorder()
+	s.GetLeaderboardHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var entries []data.LeaderboardEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ResourceProvider != "rp1" {
+		t.Fatalf("expected one entry for rp1, got %+v", entries)
+	}
+}
@@ -0,0 +1,31 @@
+package solver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+)
+
+// AddResultHandler serves POST /api/v1/results: the request body is a
+// single data.Result, as produced by jobcreator.ReceiveResult once it has
+// verified the result CAR, committed to the store via store.AddResult. It's
+// mounted wherever the rest of the solver's HTTP API is routed.
+func (solver *Solver) AddResultHandler(w http.ResponseWriter, r *http.Request) {
+	var result data.Result
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stored, err := solver.store.AddResult(r.Context(), result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stored); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/bacalhau-project/lilypad/pkg/solver/store"
+	"github.com/bacalhau-project/lilypad/pkg/system"
+	"github.com/bacalhau-project/lilypad/pkg/web3"
+)
+
+type SolverOptions struct {
+	Web3 web3.Web3Options
+	// ListenAddress is the host:port the solver's HTTP API (job offer
+	// batch submission, result submission, leaderboard) listens on.
+	ListenAddress string
+}
+
+type Solver struct {
+	options SolverOptions
+	store   store.Backend
+	web3SDK *web3.ContractSDK
+}
+
+func NewSolver(options SolverOptions, solverStore store.Backend, web3SDK *web3.ContractSDK) (*Solver, error) {
+	return &Solver{
+		options: options,
+		store:   solverStore,
+		web3SDK: web3SDK,
+	}, nil
+}
+
+// mux builds the solver's HTTP API routes. It's split out from Start so
+// tests can exercise the handlers through httptest against exactly the
+// routing a running solver serves, without binding a real listener.
+func (solver *Solver) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/job_offers/batch", solver.AddJobOffersBatchHandler)
+	mux.HandleFunc("/api/v1/results", solver.AddResultHandler)
+	mux.HandleFunc("/api/v1/leaderboard", solver.GetLeaderboardHandler)
+	return mux
+}
+
+// Handler returns the solver's HTTP API as an http.Handler, for embedding in
+// a server other than the one Start runs (e.g. a test's httptest.Server).
+func (solver *Solver) Handler() http.Handler {
+	return solver.mux()
+}
+
+// Start serves the solver's HTTP API on options.ListenAddress until ctx is
+// cancelled, at which point it shuts the server down and returns.
+func (solver *Solver) Start(ctx context.Context, cm *system.CleanupManager) error {
+	if solver.options.ListenAddress == "" {
+		return fmt.Errorf("solver requires a listen address")
+	}
+
+	listener, err := net.Listen("tcp", solver.options.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("error starting solver HTTP API listener: %w", err)
+	}
+
+	server := &http.Server{Handler: solver.mux()}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		return server.Shutdown(context.Background())
+	case err := <-serveErr:
+		return err
+	}
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/lilypad/pkg/solver/store/memory"
+)
+
+func newTestSolver(t *testing.T) *Solver {
+	t.Helper()
+	solverStore, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { solverStore.Close() })
+
+	s, err := NewSolver(SolverOptions{}, solverStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestMuxMountsJobOffersBatchResultsAndLeaderboardRoutes is a regression
+// test for Start being a no-op: it confirms the routes the handlers' own
+// doc comments promise ("mounted by Solver.mux, which Start serves") are
+// actually wired, by driving them through a real httptest server instead of
+// calling the handler funcs directly.
+func TestMuxMountsJobOffersBatchResultsAndLeaderboardRoutes(t *testing.T) {
+	s := newTestSolver(t)
+	server := httptest.NewServer(s.mux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1/job_offers/batch", "application/json", strings.NewReader("[]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the job offers batch route to be mounted, got status %d", resp.StatusCode)
+	}
+
+	resp2, err := http.Get(server.URL + "/api/v1/leaderboard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatalf("expected the leaderboard route to be mounted, got status %d", resp2.StatusCode)
+	}
+
+	resp3, err := http.Post(server.URL+"/api/v1/results", "application/json", strings.NewReader(`{"deal_id":"d1","root_cid":"bafy","car_size":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp3.Body.Close()
+	if resp3.StatusCode != http.StatusOK {
+		t.Fatalf("expected the results route to be mounted, got status %d", resp3.StatusCode)
+	}
+}
@@ -2,16 +2,66 @@ package store
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/bacalhau-project/lilypad/pkg/data"
 	"github.com/bacalhau-project/lilypad/pkg/jsonl"
 	"github.com/bacalhau-project/lilypad/pkg/solver/store"
 )
 
+// deadlineTimer is a resettable expiry signal modeled on the net package's
+// internal per-conn deadline: a cancel channel that a time.AfterFunc closes
+// once the configured timeout elapses. Each call to arm starts its own
+// timer and channel. A zero timeout disables it.
+type deadlineTimer struct {
+	mutex   sync.Mutex
+	timeout time.Duration
+}
+
+// set changes the default timeout applied to calls that arm after this
+// returns; it does not affect a wait already in progress.
+func (d *deadlineTimer) set(timeout time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.timeout = timeout
+}
+
+// arm returns a channel that closes when the call should give up: ctx's own
+// deadline, layered with a fresh timer for the store's default timeout, so
+// the operator-configured cap still applies on top of whatever deadline the
+// caller's context already carries. The returned stop func must be called
+// once the wait is over to release the timer.
+func (d *deadlineTimer) arm(ctx context.Context) (<-chan struct{}, func()) {
+	d.mutex.Lock()
+	timeout := d.timeout
+	d.mutex.Unlock()
+
+	if timeout <= 0 {
+		return ctx.Done(), func() {}
+	}
+
+	expired := make(chan struct{})
+	timer := time.AfterFunc(timeout, func() { close(expired) })
+
+	merged := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-expired:
+		}
+		close(merged)
+	}()
+
+	return merged, func() { timer.Stop() }
+}
+
 type SolverStoreMemory struct {
 	jobOfferMap      map[string]*data.JobOfferContainer
 	resourceOfferMap map[string]*data.ResourceOfferContainer
@@ -20,6 +70,19 @@ type SolverStoreMemory struct {
 	matchDecisionMap map[string]*data.MatchDecision
 	mutex            sync.RWMutex
 	logWriters       map[string]jsonl.Writer
+
+	// deadline bounds how long a call waits to acquire mutex; writeDeadline
+	// additionally bounds how long a mutating call waits for its JSONL
+	// write to flush once the mutex is held.
+	deadline      deadlineTimer
+	writeDeadline deadlineTimer
+
+	// inFlightWrites counts the background goroutines writeLog/writeLogBatch
+	// spawn to do the actual JSONL write, including ones left running past
+	// writeDeadline (see writeLog). Compact waits on this before closing or
+	// reassigning a kind's writer, so an orphaned write can't have its file
+	// closed out from under it mid-write.
+	inFlightWrites sync.WaitGroup
 }
 
 func getMatchID(resourceOffer string, jobOffer string) string {
@@ -76,47 +139,90 @@ func getJSONLFilename(kind string) string {
 	return fmt.Sprintf("/var/tmp/lilypad_%s.jsonl", kind)
 }
 
+func getSnapshotFilename(kind string) string {
+	return fmt.Sprintf("/var/tmp/lilypad_%s.snapshot", kind)
+}
+
+// loadPersistedMap rebuilds a kind's map from its last snapshot plus
+// whatever the log has accumulated since, so a restart only replays events
+// written after the last compaction instead of every event ever written.
+func loadPersistedMap[T any](kind string, getID func(*T) string) (map[string]*T, error) {
+	records, err := loadJSONLMap[T](getSnapshotFilename(kind), getID)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = map[string]*T{}
+	}
+
+	logRecords, err := loadJSONLFile[T](getJSONLFilename(kind))
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range logRecords {
+		records[getID(record)] = record
+	}
+
+	return records, nil
+}
+
+// persistedKinds lists every record kind NewSolverStoreMemory persists to
+// /var/tmp/lilypad_*.jsonl.
+var persistedKinds = []string{"job_offers", "resource_offers", "deals", "decisions", "results"}
+
+// HasPersistedState reports whether a prior memory-backed run left any
+// /var/tmp/lilypad_*.jsonl or .snapshot file behind.
+func HasPersistedState() bool {
+	for _, kind := range persistedKinds {
+		if _, err := os.Stat(getJSONLFilename(kind)); err == nil {
+			return true
+		}
+		if _, err := os.Stat(getSnapshotFilename(kind)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func NewSolverStoreMemory() (*SolverStoreMemory, error) {
 	logWriters := make(map[string]jsonl.Writer)
 
-	kinds := []string{"job_offers", "resource_offers", "deals", "decisions", "results"}
-
-	jobOffers, err := loadJSONLMap[data.JobOfferContainer](getJSONLFilename("job_offers"), func(jobOffer *data.JobOfferContainer) string {
+	jobOffers, err := loadPersistedMap[data.JobOfferContainer]("job_offers", func(jobOffer *data.JobOfferContainer) string {
 		return jobOffer.ID
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resourceOffers, err := loadJSONLMap[data.ResourceOfferContainer](getJSONLFilename("resource_offers"), func(resourceOffer *data.ResourceOfferContainer) string {
+	resourceOffers, err := loadPersistedMap[data.ResourceOfferContainer]("resource_offers", func(resourceOffer *data.ResourceOfferContainer) string {
 		return resourceOffer.ID
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	deals, err := loadJSONLMap[data.DealContainer](getJSONLFilename("deals"), func(deal *data.DealContainer) string {
+	deals, err := loadPersistedMap[data.DealContainer]("deals", func(deal *data.DealContainer) string {
 		return deal.ID
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := loadJSONLMap[data.Result](getJSONLFilename("results"), func(result *data.Result) string {
+	results, err := loadPersistedMap[data.Result]("results", func(result *data.Result) string {
 		return result.DealID
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	decisions, err := loadJSONLMap[data.MatchDecision](getJSONLFilename("decisions"), func(decision *data.MatchDecision) string {
+	decisions, err := loadPersistedMap[data.MatchDecision]("decisions", func(decision *data.MatchDecision) string {
 		return getMatchID(decision.ResourceOffer, decision.JobOffer)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	for _, kind := range kinds {
+	for _, kind := range persistedKinds {
 		logfile, err := os.OpenFile(getJSONLFilename(kind), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
 		if err != nil {
 			return nil, err
@@ -134,359 +240,849 @@ func NewSolverStoreMemory() (*SolverStoreMemory, error) {
 	}, nil
 }
 
-func (s *SolverStoreMemory) AddJobOffer(jobOffer data.JobOfferContainer) (*data.JobOfferContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.jobOfferMap[jobOffer.ID] = &jobOffer
-	s.logWriters["job_offers"].Write(jobOffer)
+// SetDeadline implements store.SolverStore.
+func (s *SolverStoreMemory) SetDeadline(d time.Duration) {
+	s.deadline.set(d)
+}
+
+// SetWriteDeadline implements store.SolverStore.
+func (s *SolverStoreMemory) SetWriteDeadline(d time.Duration) {
+	s.writeDeadline.set(d)
+}
+
+// withLock runs fn while holding mutex (RLock if readOnly, Lock otherwise),
+// giving up with context.DeadlineExceeded (or ctx's own error) if ctx fires
+// before the lock is acquired. If that happens, acquisition is left to
+// finish on a background goroutine that releases the lock as soon as it
+// lands, so a call that timed out waiting can't wedge every call after it.
+func (s *SolverStoreMemory) withLock(ctx context.Context, readOnly bool, fn func() error) error {
+	expired, stop := s.deadline.arm(ctx)
+	defer stop()
+
+	acquired := make(chan struct{})
+	go func() {
+		if readOnly {
+			s.mutex.RLock()
+		} else {
+			s.mutex.Lock()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+	case <-expired:
+		go func() {
+			<-acquired
+			if readOnly {
+				s.mutex.RUnlock()
+			} else {
+				s.mutex.Unlock()
+			}
+		}()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+
+	defer func() {
+		if readOnly {
+			s.mutex.RUnlock()
+		} else {
+			s.mutex.Unlock()
+		}
+	}()
+
+	return fn()
+}
+
+// writeLog appends record to kind's JSONL log, bounded by writeDeadline on
+// top of whatever withLock already waited. A write that's still in flight
+// when the deadline fires is drained on a background goroutine instead of
+// left to block the writer's internal lock for the next caller.
+func (s *SolverStoreMemory) writeLog(ctx context.Context, kind string, record any) error {
+	expired, stop := s.writeDeadline.arm(ctx)
+	defer stop()
+
+	// Captured here, while the caller still holds s.mutex, so the goroutine
+	// below never touches s.logWriters itself: if the deadline fires it's
+	// left running after withLock has released the lock, and Compact can
+	// reassign s.logWriters[kind] concurrently from under it.
+	writer := s.logWriters[kind]
+	done := make(chan error, 1)
+	s.inFlightWrites.Add(1)
+	go func() {
+		defer s.inFlightWrites.Done()
+		done <- writer.Write(record)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		go func() { <-done }()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+}
+
+// writeLogBatch is like writeLog but appends every record in records in one
+// jsonl.Writer.WriteBatch call.
+func (s *SolverStoreMemory) writeLogBatch(ctx context.Context, kind string, records []any) error {
+	expired, stop := s.writeDeadline.arm(ctx)
+	defer stop()
+
+	// See writeLog: captured under the caller's lock so the goroutine never
+	// reads s.logWriters itself once it's potentially orphaned.
+	writer := s.logWriters[kind]
+	done := make(chan error, 1)
+	s.inFlightWrites.Add(1)
+	go func() {
+		defer s.inFlightWrites.Done()
+		done <- writer.WriteBatch(records)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		go func() { <-done }()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+}
+
+func (s *SolverStoreMemory) AddJobOffer(ctx context.Context, jobOffer data.JobOfferContainer) (*data.JobOfferContainer, error) {
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		s.jobOfferMap[jobOffer.ID] = &jobOffer
+		writeErr = s.writeLog(ctx, "job_offers", jobOffer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
 	return &jobOffer, nil
 }
 
-func (s *SolverStoreMemory) AddResourceOffer(resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.resourceOfferMap[resourceOffer.ID] = &resourceOffer
-	s.logWriters["resource_offers"].Write(resourceOffer)
+// AddJobOffersBatch implements store.Tx under a single mutex acquisition
+// and a single jsonl.Writer.WriteBatch call.
+func (s *SolverStoreMemory) AddJobOffersBatch(ctx context.Context, jobOffers []data.JobOfferContainer) ([]data.JobOfferContainer, error) {
+	if len(jobOffers) == 0 {
+		return nil, nil
+	}
+
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		records := make([]any, len(jobOffers))
+		for i := range jobOffers {
+			records[i] = jobOffers[i]
+		}
+
+		writeErr = s.writeLogBatch(ctx, "job_offers", records)
+		if writeErr != nil {
+			return nil
+		}
+		for i := range jobOffers {
+			s.jobOfferMap[jobOffers[i].ID] = &jobOffers[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return jobOffers, nil
+}
+
+func (s *SolverStoreMemory) AddResourceOffer(ctx context.Context, resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error) {
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		s.resourceOfferMap[resourceOffer.ID] = &resourceOffer
+		writeErr = s.writeLog(ctx, "resource_offers", resourceOffer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
 	return &resourceOffer, nil
 }
 
-func (s *SolverStoreMemory) AddDeal(deal data.DealContainer) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.dealMap[deal.ID] = &deal
-	s.logWriters["deals"].Write(deal)
+func (s *SolverStoreMemory) AddDeal(ctx context.Context, deal data.DealContainer) (*data.DealContainer, error) {
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		s.dealMap[deal.ID] = &deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
 	return &deal, nil
 }
 
-func (s *SolverStoreMemory) AddResult(result data.Result) (*data.Result, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.resultMap[result.DealID] = &result
-	s.logWriters["results"].Write(result)
+func (s *SolverStoreMemory) AddResult(ctx context.Context, result data.Result) (*data.Result, error) {
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		s.resultMap[result.DealID] = &result
+		writeErr = s.writeLog(ctx, "results", result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
 	return &result, nil
 }
 
-func (s *SolverStoreMemory) AddMatchDecision(resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	id := getMatchID(resourceOffer, jobOffer)
-	_, ok := s.matchDecisionMap[id]
-	if ok {
-		return nil, fmt.Errorf("that match already exists")
-	}
-	decision := &data.MatchDecision{
-		ResourceOffer: resourceOffer,
-		JobOffer:      jobOffer,
-		Deal:          deal,
-		Result:        result,
-	}
-	s.matchDecisionMap[id] = decision
-	s.logWriters["decisions"].Write(decision)
+// AddResultsBatch implements store.Tx; see AddJobOffersBatch for the shape.
+func (s *SolverStoreMemory) AddResultsBatch(ctx context.Context, results []data.Result) ([]data.Result, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	var writeErr error
+	err := s.withLock(ctx, false, func() error {
+		records := make([]any, len(results))
+		for i := range results {
+			records[i] = results[i]
+		}
+
+		writeErr = s.writeLogBatch(ctx, "results", records)
+		if writeErr != nil {
+			return nil
+		}
+		for i := range results {
+			s.resultMap[results[i].DealID] = &results[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return results, nil
+}
+
+func (s *SolverStoreMemory) AddMatchDecision(ctx context.Context, resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error) {
+	var decision *data.MatchDecision
+	var addErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		id := getMatchID(resourceOffer, jobOffer)
+		if _, ok := s.matchDecisionMap[id]; ok {
+			addErr = fmt.Errorf("that match already exists")
+			return nil
+		}
+		decision = &data.MatchDecision{
+			ResourceOffer: resourceOffer,
+			JobOffer:      jobOffer,
+			Deal:          deal,
+			Result:        result,
+		}
+		s.matchDecisionMap[id] = decision
+		writeErr = s.writeLog(ctx, "decisions", decision)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if addErr != nil {
+		return nil, addErr
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
 	return decision, nil
 }
 
-func (s *SolverStoreMemory) GetJobOffers(query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+func (s *SolverStoreMemory) GetJobOffers(ctx context.Context, query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
 	jobOffers := []data.JobOfferContainer{}
-	for _, jobOffer := range s.jobOfferMap {
-		matching := true
-		if query.JobCreator != "" && jobOffer.JobCreator != query.JobCreator {
-			matching = false
-		}
-		if query.NotMatched {
-			if jobOffer.DealID != "" {
+	err := s.withLock(ctx, true, func() error {
+		for _, jobOffer := range s.jobOfferMap {
+			matching := true
+			if query.JobCreator != "" && jobOffer.JobCreator != query.JobCreator {
 				matching = false
 			}
+			if query.NotMatched {
+				if jobOffer.DealID != "" {
+					matching = false
+				}
+			}
+			if matching {
+				jobOffers = append(jobOffers, *jobOffer)
+			}
 		}
-		if matching {
-			jobOffers = append(jobOffers, *jobOffer)
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return jobOffers, nil
 }
 
-func (s *SolverStoreMemory) GetResourceOffers(query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+func (s *SolverStoreMemory) GetResourceOffers(ctx context.Context, query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
 	resourceOffers := []data.ResourceOfferContainer{}
-	for _, resourceOffer := range s.resourceOfferMap {
-		matching := true
-		if query.ResourceProvider != "" && resourceOffer.ResourceProvider != query.ResourceProvider {
-			matching = false
-		}
-		if query.Active && !data.IsActiveAgreementState(resourceOffer.State) {
-			matching = false
-		}
-		if query.NotMatched {
-			if resourceOffer.DealID != "" {
+	err := s.withLock(ctx, true, func() error {
+		for _, resourceOffer := range s.resourceOfferMap {
+			matching := true
+			if query.ResourceProvider != "" && resourceOffer.ResourceProvider != query.ResourceProvider {
 				matching = false
 			}
+			if query.Active && !data.IsActiveAgreementState(resourceOffer.State) {
+				matching = false
+			}
+			if query.NotMatched {
+				if resourceOffer.DealID != "" {
+					matching = false
+				}
+			}
+			if matching {
+				resourceOffers = append(resourceOffers, *resourceOffer)
+			}
 		}
-		if matching {
-			resourceOffers = append(resourceOffers, *resourceOffer)
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return resourceOffers, nil
 }
 
-func (s *SolverStoreMemory) GetDeals(query store.GetDealsQuery) ([]data.DealContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+func (s *SolverStoreMemory) GetDeals(ctx context.Context, query store.GetDealsQuery) ([]data.DealContainer, error) {
 	deals := []data.DealContainer{}
-	queryState := uint8(0)
-	if query.State != "" {
-		parsedState, err := data.GetAgreementState(query.State)
-		if err != nil {
-			return nil, err
-		}
-		queryState = parsedState
-	}
-	for _, deal := range s.dealMap {
-		matching := true
-		if query.JobCreator != "" && deal.JobCreator != query.JobCreator {
-			matching = false
-		}
-		if query.ResourceProvider != "" && deal.ResourceProvider != query.ResourceProvider {
-			matching = false
-		}
-		if query.Mediator != "" && deal.Mediator != query.Mediator {
-			matching = false
-		}
-		if query.State != "" && deal.State != queryState {
-			matching = false
+	err := s.withLock(ctx, true, func() error {
+		queryState := uint8(0)
+		if query.State != "" {
+			parsedState, err := data.GetAgreementState(query.State)
+			if err != nil {
+				return err
+			}
+			queryState = parsedState
 		}
-		if matching {
-			deals = append(deals, *deal)
+		for _, deal := range s.dealMap {
+			matching := true
+			if query.JobCreator != "" && deal.JobCreator != query.JobCreator {
+				matching = false
+			}
+			if query.ResourceProvider != "" && deal.ResourceProvider != query.ResourceProvider {
+				matching = false
+			}
+			if query.Mediator != "" && deal.Mediator != query.Mediator {
+				matching = false
+			}
+			if query.State != "" && deal.State != queryState {
+				matching = false
+			}
+			if matching {
+				deals = append(deals, *deal)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return deals, nil
 }
 
-func (s *SolverStoreMemory) GetJobOffer(id string) (*data.JobOfferContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	jobOffer, ok := s.jobOfferMap[id]
-	if !ok {
-		return nil, nil
+func (s *SolverStoreMemory) GetJobOffer(ctx context.Context, id string) (*data.JobOfferContainer, error) {
+	var jobOffer *data.JobOfferContainer
+	err := s.withLock(ctx, true, func() error {
+		jobOffer = s.jobOfferMap[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return jobOffer, nil
 }
 
-func (s *SolverStoreMemory) GetResourceOffer(id string) (*data.ResourceOfferContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	resourceOffer, ok := s.resourceOfferMap[id]
-	if !ok {
-		return nil, nil
+func (s *SolverStoreMemory) GetResourceOffer(ctx context.Context, id string) (*data.ResourceOfferContainer, error) {
+	var resourceOffer *data.ResourceOfferContainer
+	err := s.withLock(ctx, true, func() error {
+		resourceOffer = s.resourceOfferMap[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return resourceOffer, nil
 }
 
-func (s *SolverStoreMemory) GetDeal(id string) (*data.DealContainer, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, nil
+func (s *SolverStoreMemory) GetDeal(ctx context.Context, id string) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	err := s.withLock(ctx, true, func() error {
+		deal = s.dealMap[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return deal, nil
 }
 
-func (s *SolverStoreMemory) GetResult(id string) (*data.Result, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	result, ok := s.resultMap[id]
-	if !ok {
-		return nil, nil
+func (s *SolverStoreMemory) GetResult(ctx context.Context, id string) (*data.Result, error) {
+	var result *data.Result
+	err := s.withLock(ctx, true, func() error {
+		result = s.resultMap[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
-func (s *SolverStoreMemory) GetMatchDecision(resourceOffer string, jobOffer string) (*data.MatchDecision, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	id := getMatchID(resourceOffer, jobOffer)
-	decision, ok := s.matchDecisionMap[id]
-	if !ok {
-		return nil, nil
+func (s *SolverStoreMemory) GetMatchDecision(ctx context.Context, resourceOffer string, jobOffer string) (*data.MatchDecision, error) {
+	var decision *data.MatchDecision
+	err := s.withLock(ctx, true, func() error {
+		decision = s.matchDecisionMap[getMatchID(resourceOffer, jobOffer)]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return decision, nil
 }
 
-func (s *SolverStoreMemory) UpdateJobOfferState(id string, dealID string, state uint8) (*data.JobOfferContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	jobOffer, ok := s.jobOfferMap[id]
-	if !ok {
-		return nil, fmt.Errorf("job offer not found: %s", id)
-	}
-	jobOffer.DealID = dealID
-	jobOffer.State = state
-	s.jobOfferMap[id] = jobOffer
-	s.logWriters["job_offers"].Write(jobOffer)
-	return jobOffer, nil
+// GetResults returns every result the store currently holds.
+func (s *SolverStoreMemory) GetResults(ctx context.Context) ([]data.Result, error) {
+	results := []data.Result{}
+	err := s.withLock(ctx, true, func() error {
+		for _, result := range s.resultMap {
+			results = append(results, *result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (s *SolverStoreMemory) UpdateResourceOfferState(id string, dealID string, state uint8) (*data.ResourceOfferContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	resourceOffer, ok := s.resourceOfferMap[id]
-	if !ok {
-		return nil, fmt.Errorf("resource offer not found: %s", id)
-	}
-	resourceOffer.DealID = dealID
-	resourceOffer.State = state
-	s.resourceOfferMap[id] = resourceOffer
-	s.logWriters["resource_offers"].Write(resourceOffer)
-	return resourceOffer, nil
+// GetMatchDecisions returns every match decision the store currently holds;
+// see GetResults.
+func (s *SolverStoreMemory) GetMatchDecisions(ctx context.Context) ([]data.MatchDecision, error) {
+	decisions := []data.MatchDecision{}
+	err := s.withLock(ctx, true, func() error {
+		for _, decision := range s.matchDecisionMap {
+			decisions = append(decisions, *decision)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decisions, nil
 }
 
-func (s *SolverStoreMemory) UpdateDealState(id string, state uint8) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, fmt.Errorf("deal not found: %s", id)
+// Close releases the store's open log file handles.
+func (s *SolverStoreMemory) Close() error {
+	for _, writer := range s.logWriters {
+		if closer, ok := writer.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return err
+			}
+		}
 	}
-	deal.State = state
-	s.dealMap[id] = deal
-	s.logWriters["deals"].Write(deal)
-	return deal, nil
+	return nil
 }
 
-func (s *SolverStoreMemory) UpdateDealMediator(id string, mediator string) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, fmt.Errorf("deal not found: %s", id)
+func (s *SolverStoreMemory) UpdateJobOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.JobOfferContainer, error) {
+	var jobOffer *data.JobOfferContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		jobOffer, ok = s.jobOfferMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("job offer not found: %s", id)
+			return nil
+		}
+		jobOffer.DealID = dealID
+		jobOffer.State = state
+		s.jobOfferMap[id] = jobOffer
+		writeErr = s.writeLog(ctx, "job_offers", jobOffer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	deal.Mediator = mediator
-	s.dealMap[id] = deal
-	s.logWriters["deals"].Write(deal)
-	return deal, nil
+	if notFoundErr != nil {
+		return nil, notFoundErr
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return jobOffer, nil
 }
 
-func (s *SolverStoreMemory) UpdateDealTransactionsResourceProvider(id string, data data.DealTransactionsResourceProvider) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, fmt.Errorf("deal not found: %s", id)
+func (s *SolverStoreMemory) UpdateResourceOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.ResourceOfferContainer, error) {
+	var resourceOffer *data.ResourceOfferContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		resourceOffer, ok = s.resourceOfferMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("resource offer not found: %s", id)
+			return nil
+		}
+		resourceOffer.DealID = dealID
+		resourceOffer.State = state
+		s.resourceOfferMap[id] = resourceOffer
+		writeErr = s.writeLog(ctx, "resource_offers", resourceOffer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	txs := &deal.Transactions.ResourceProvider
-	if data.Agree != "" {
-		txs.Agree = data.Agree
+	if notFoundErr != nil {
+		return nil, notFoundErr
 	}
-	if data.AddResult != "" {
-		txs.AddResult = data.AddResult
+	if writeErr != nil {
+		return nil, writeErr
 	}
-	if data.TimeoutAgree != "" {
-		txs.TimeoutAgree = data.TimeoutAgree
+	return resourceOffer, nil
+}
+
+func (s *SolverStoreMemory) UpdateDealState(ctx context.Context, id string, state uint8) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		deal, ok = s.dealMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("deal not found: %s", id)
+			return nil
+		}
+		deal.State = state
+		s.dealMap[id] = deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if data.TimeoutJudgeResult != "" {
-		txs.TimeoutJudgeResult = data.TimeoutJudgeResult
+	if notFoundErr != nil {
+		return nil, notFoundErr
 	}
-	if data.TimeoutMediateResult != "" {
-		txs.TimeoutMediateResult = data.TimeoutMediateResult
+	if writeErr != nil {
+		return nil, writeErr
 	}
-	s.dealMap[id] = deal
-	s.logWriters["deals"].Write(deal)
 	return deal, nil
 }
 
-func (s *SolverStoreMemory) UpdateDealTransactionsJobCreator(id string, data data.DealTransactionsJobCreator) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, fmt.Errorf("deal not found: %s", id)
-	}
-	txs := &deal.Transactions.JobCreator
-	if data.Agree != "" {
-		txs.Agree = data.Agree
+func (s *SolverStoreMemory) UpdateDealMediator(ctx context.Context, id string, mediator string) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		deal, ok = s.dealMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("deal not found: %s", id)
+			return nil
+		}
+		deal.Mediator = mediator
+		s.dealMap[id] = deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if data.AcceptResult != "" {
-		txs.AcceptResult = data.AcceptResult
+	if notFoundErr != nil {
+		return nil, notFoundErr
 	}
-	if data.CheckResult != "" {
-		txs.CheckResult = data.CheckResult
+	if writeErr != nil {
+		return nil, writeErr
 	}
-	if data.TimeoutAgree != "" {
-		txs.TimeoutAgree = data.TimeoutAgree
+	return deal, nil
+}
+
+func (s *SolverStoreMemory) UpdateDealTransactionsResourceProvider(ctx context.Context, id string, transactions data.DealTransactionsResourceProvider) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		deal, ok = s.dealMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("deal not found: %s", id)
+			return nil
+		}
+		txs := &deal.Transactions.ResourceProvider
+		if transactions.Agree != "" {
+			txs.Agree = transactions.Agree
+		}
+		if transactions.AddResult != "" {
+			txs.AddResult = transactions.AddResult
+		}
+		if transactions.TimeoutAgree != "" {
+			txs.TimeoutAgree = transactions.TimeoutAgree
+		}
+		if transactions.TimeoutJudgeResult != "" {
+			txs.TimeoutJudgeResult = transactions.TimeoutJudgeResult
+		}
+		if transactions.TimeoutMediateResult != "" {
+			txs.TimeoutMediateResult = transactions.TimeoutMediateResult
+		}
+		s.dealMap[id] = deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if data.TimeoutSubmitResult != "" {
-		txs.TimeoutSubmitResult = data.TimeoutSubmitResult
+	if notFoundErr != nil {
+		return nil, notFoundErr
 	}
-	if data.TimeoutMediateResult != "" {
-		txs.TimeoutMediateResult = data.TimeoutMediateResult
+	if writeErr != nil {
+		return nil, writeErr
 	}
-	s.dealMap[id] = deal
-	s.logWriters["deals"].Write(deal)
 	return deal, nil
 }
 
-func (s *SolverStoreMemory) UpdateDealTransactionsMediator(id string, data data.DealTransactionsMediator) (*data.DealContainer, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	deal, ok := s.dealMap[id]
-	if !ok {
-		return nil, fmt.Errorf("deal not found: %s", id)
+func (s *SolverStoreMemory) UpdateDealTransactionsJobCreator(ctx context.Context, id string, transactions data.DealTransactionsJobCreator) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		deal, ok = s.dealMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("deal not found: %s", id)
+			return nil
+		}
+		txs := &deal.Transactions.JobCreator
+		if transactions.Agree != "" {
+			txs.Agree = transactions.Agree
+		}
+		if transactions.AcceptResult != "" {
+			txs.AcceptResult = transactions.AcceptResult
+		}
+		if transactions.CheckResult != "" {
+			txs.CheckResult = transactions.CheckResult
+		}
+		if transactions.TimeoutAgree != "" {
+			txs.TimeoutAgree = transactions.TimeoutAgree
+		}
+		if transactions.TimeoutSubmitResult != "" {
+			txs.TimeoutSubmitResult = transactions.TimeoutSubmitResult
+		}
+		if transactions.TimeoutMediateResult != "" {
+			txs.TimeoutMediateResult = transactions.TimeoutMediateResult
+		}
+		s.dealMap[id] = deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	txs := &deal.Transactions.Mediator
-	if data.MediationAcceptResult != "" {
-		txs.MediationAcceptResult = data.MediationAcceptResult
+	if notFoundErr != nil {
+		return nil, notFoundErr
 	}
-	if data.MediationRejectResult != "" {
-		txs.MediationRejectResult = data.MediationRejectResult
+	if writeErr != nil {
+		return nil, writeErr
 	}
-	s.dealMap[id] = deal
-	s.logWriters["deals"].Write(deal)
 	return deal, nil
 }
 
-func (s *SolverStoreMemory) RemoveJobOffer(id string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	delete(s.jobOfferMap, id)
-	return nil
+func (s *SolverStoreMemory) UpdateDealTransactionsMediator(ctx context.Context, id string, transactions data.DealTransactionsMediator) (*data.DealContainer, error) {
+	var deal *data.DealContainer
+	var notFoundErr, writeErr error
+	err := s.withLock(ctx, false, func() error {
+		var ok bool
+		deal, ok = s.dealMap[id]
+		if !ok {
+			notFoundErr = fmt.Errorf("deal not found: %s", id)
+			return nil
+		}
+		txs := &deal.Transactions.Mediator
+		if transactions.MediationAcceptResult != "" {
+			txs.MediationAcceptResult = transactions.MediationAcceptResult
+		}
+		if transactions.MediationRejectResult != "" {
+			txs.MediationRejectResult = transactions.MediationRejectResult
+		}
+		s.dealMap[id] = deal
+		writeErr = s.writeLog(ctx, "deals", deal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if notFoundErr != nil {
+		return nil, notFoundErr
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return deal, nil
 }
 
-func (s *SolverStoreMemory) RemoveResourceOffer(id string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	delete(s.resourceOfferMap, id)
-	return nil
+func (s *SolverStoreMemory) RemoveJobOffer(ctx context.Context, id string) error {
+	return s.withLock(ctx, false, func() error {
+		delete(s.jobOfferMap, id)
+		return nil
+	})
 }
 
-func (s *SolverStoreMemory) GetLeaderboardData() ([]data.LeaderboardEntry, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+func (s *SolverStoreMemory) RemoveResourceOffer(ctx context.Context, id string) error {
+	return s.withLock(ctx, false, func() error {
+		delete(s.resourceOfferMap, id)
+		return nil
+	})
+}
 
-	countPerResourceProvider := map[string]int{}
+func (s *SolverStoreMemory) GetLeaderboardData(ctx context.Context, query store.GetLeaderboardQuery) ([]data.LeaderboardEntry, error) {
+	comparator, err := data.GetLeaderboardComparator(query.SortBy)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, deal := range s.dealMap {
-		if data.IsSuccessfulAgreementState(deal.State) {
-			currentCount, ok := countPerResourceProvider[deal.ResourceProvider]
+	entryMap := map[string]*data.LeaderboardEntry{}
+	err = s.withLock(ctx, false, func() error {
+		for _, deal := range s.dealMap {
+			if query.ResourceProvider != "" && deal.ResourceProvider != query.ResourceProvider {
+				continue
+			}
+			entry, ok := entryMap[deal.ResourceProvider]
 			if !ok {
-				currentCount = 0
+				entry = &data.LeaderboardEntry{ResourceProvider: deal.ResourceProvider}
+				entryMap[deal.ResourceProvider] = entry
+			}
+			switch {
+			case data.IsSuccessfulAgreementState(deal.State):
+				entry.JobCount++
+			case deal.State == data.AgreementStateResultsRejected || deal.State == data.AgreementStateMediationRejected:
+				entry.FailureCount++
+			case deal.State == data.AgreementStateTimeout:
+				entry.TimeoutCount++
 			}
-			countPerResourceProvider[deal.ResourceProvider] = currentCount + 1
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	results := []data.LeaderboardEntry{}
+	results := make([]data.LeaderboardEntry, 0, len(entryMap))
+	for _, entry := range entryMap {
+		results = append(results, *entry)
+	}
 
-	for resourceProvider, count := range countPerResourceProvider {
-		results = append(results, data.LeaderboardEntry{
-			ResourceProvider: resourceProvider,
-			JobCount:         count,
-		})
+	sort.Slice(results, func(i, j int) bool {
+		return comparator(results[i], results[j]) < 0
+	})
+
+	if query.Limit > 0 && query.Limit < len(results) {
+		results = results[:query.Limit]
 	}
 
 	return results, nil
 }
 
+// Compact folds the current in-memory state of every kind into its
+// `.snapshot` file and truncates the corresponding log, so the next restart
+// replays only the events written since this call instead of every event
+// ever written.
+func (s *SolverStoreMemory) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// A write that ran past writeDeadline is left running in the background
+	// holding the writer being replaced below (see writeLog); wait for it to
+	// finish with that writer before closing its file and swapping in a new
+	// one, or it can write into a closed/truncated file underneath it.
+	s.inFlightWrites.Wait()
+
+	if err := writeSnapshot(getSnapshotFilename("job_offers"), s.jobOfferMap); err != nil {
+		return err
+	}
+	if err := writeSnapshot(getSnapshotFilename("resource_offers"), s.resourceOfferMap); err != nil {
+		return err
+	}
+	if err := writeSnapshot(getSnapshotFilename("deals"), s.dealMap); err != nil {
+		return err
+	}
+	if err := writeSnapshot(getSnapshotFilename("results"), s.resultMap); err != nil {
+		return err
+	}
+	if err := writeSnapshot(getSnapshotFilename("decisions"), s.matchDecisionMap); err != nil {
+		return err
+	}
+
+	for kind, writer := range s.logWriters {
+		if closer, ok := writer.(io.Closer); ok {
+			closer.Close()
+		}
+		logfile, err := os.OpenFile(getJSONLFilename(kind), os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		s.logWriters[kind] = jsonl.NewWriter(logfile)
+	}
+
+	return nil
+}
+
+// StartCompaction runs Compact on the given interval until ctx is done, so
+// operators don't have to compact by hand on long-running solvers.
+func (s *SolverStoreMemory) StartCompaction(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Compact(); err != nil {
+					fmt.Printf("Error compacting solver store: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+func writeSnapshot[T any](filename string, records map[string]*T) error {
+	tmpFilename := filename + ".tmp"
+	tmpfile, err := os.OpenFile(tmpFilename, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	writer := jsonl.NewWriter(tmpfile)
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			tmpfile.Close()
+			return err
+		}
+	}
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilename, filename)
+}
+
 // Compile-time interface check:
 var _ store.SolverStore = (*SolverStoreMemory)(nil)
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/jsonl"
+)
+
+func newTestStore() *SolverStoreMemory {
+	return &SolverStoreMemory{
+		jobOfferMap:      map[string]*data.JobOfferContainer{},
+		resourceOfferMap: map[string]*data.ResourceOfferContainer{},
+		dealMap:          map[string]*data.DealContainer{},
+		resultMap:        map[string]*data.Result{},
+		matchDecisionMap: map[string]*data.MatchDecision{},
+		logWriters: map[string]jsonl.Writer{
+			"job_offers":      jsonl.NewWriter(io.Discard),
+			"resource_offers": jsonl.NewWriter(io.Discard),
+			"deals":           jsonl.NewWriter(io.Discard),
+			"results":         jsonl.NewWriter(io.Discard),
+			"decisions":       jsonl.NewWriter(io.Discard),
+		},
+	}
+}
+
+// Regression test for the bug where arm deferred entirely to ctx's own
+// deadline, silently skipping the store's configured cap whenever the
+// caller's context already had any deadline at all.
+func TestDeadlineTimerArmLayersOnTopOfContextDeadline(t *testing.T) {
+	var dt deadlineTimer
+	dt.set(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	start := time.Now()
+	expired, stop := dt.arm(ctx)
+	defer stop()
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("arm did not honor the store's own timeout on top of ctx's deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("arm took %v, expected it to fire around the store's 20ms timeout", elapsed)
+	}
+}
+
+func TestDeadlineTimerArmRespectsContextCancellation(t *testing.T) {
+	var dt deadlineTimer
+	dt.set(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	expired, stop := dt.arm(ctx)
+	defer stop()
+	cancel()
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("arm did not fire when ctx was canceled")
+	}
+}
+
+func TestAddJobOffersBatchAddsAllRecords(t *testing.T) {
+	s := newTestStore()
+	offers := []data.JobOfferContainer{{ID: "a"}, {ID: "b"}}
+
+	got, err := s.AddJobOffersBatch(context.Background(), offers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 records returned, got %d", len(got))
+	}
+	if _, ok := s.jobOfferMap["a"]; !ok {
+		t.Error("job offer a not added to the map")
+	}
+	if _, ok := s.jobOfferMap["b"]; !ok {
+		t.Error("job offer b not added to the map")
+	}
+}
+
+func TestAddJobOffersBatchEmptyIsNoop(t *testing.T) {
+	s := newTestStore()
+	got, err := s.AddJobOffersBatch(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected nil for an empty batch, got %v", got)
+	}
+	if len(s.jobOfferMap) != 0 {
+		t.Fatalf("expected no job offers added, got %d", len(s.jobOfferMap))
+	}
+}
+
+func TestAddResultsBatchAddsAllRecords(t *testing.T) {
+	s := newTestStore()
+	results := []data.Result{{DealID: "a"}, {DealID: "b"}}
+
+	got, err := s.AddResultsBatch(context.Background(), results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 records returned, got %d", len(got))
+	}
+	if _, ok := s.resultMap["a"]; !ok {
+		t.Error("result a not added to the map")
+	}
+	if _, ok := s.resultMap["b"]; !ok {
+		t.Error("result b not added to the map")
+	}
+}
+
+// blockingWriter is a jsonl.Writer whose Write blocks until unblock is
+// closed, used to simulate a write still running past writeDeadline.
+type blockingWriter struct {
+	unblock   chan struct{}
+	writeDone chan struct{}
+}
+
+func (w *blockingWriter) Write(record any) error {
+	<-w.unblock
+	close(w.writeDone)
+	return nil
+}
+
+func (w *blockingWriter) WriteBatch(records []any) error {
+	<-w.unblock
+	close(w.writeDone)
+	return nil
+}
+
+// Regression test for the bug where Compact could close and reassign a
+// kind's writer while a write that had run past writeDeadline was still
+// running against it in the background, silently dropping or corrupting
+// that write instead of merely delaying it.
+func TestCompactWaitsForOrphanedWriteBeforeReplacingWriter(t *testing.T) {
+	s := newTestStore()
+	s.writeDeadline.set(10 * time.Millisecond)
+
+	blocking := &blockingWriter{unblock: make(chan struct{}), writeDone: make(chan struct{})}
+	s.logWriters["job_offers"] = blocking
+
+	if err := s.writeLog(context.Background(), "job_offers", map[string]string{"id": "x"}); err == nil {
+		t.Fatal("expected writeLog to time out while the writer is blocked")
+	}
+
+	compactDone := make(chan error, 1)
+	go func() { compactDone <- s.Compact() }()
+
+	select {
+	case <-compactDone:
+		t.Fatal("expected Compact to block on the still-running orphaned write")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(blocking.unblock)
+	<-blocking.writeDone
+
+	select {
+	case err := <-compactDone:
+		if err != nil {
+			t.Fatalf("unexpected error from Compact: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Compact to finish once the orphaned write completed")
+	}
+}
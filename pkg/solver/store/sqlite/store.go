@@ -0,0 +1,943 @@
+// Package store implements the solver's SolverStore interface on top of
+// SQLite, as an alternative to the JSONL-backed memory.SolverStoreMemory for
+// operators who want indexed queries and bounded startup time instead of
+// replaying an append-only log.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store"
+	memory "github.com/bacalhau-project/lilypad/pkg/solver/store/memory"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS job_offers (
+	id TEXT PRIMARY KEY,
+	job_creator TEXT NOT NULL,
+	deal_id TEXT NOT NULL,
+	state INTEGER NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_job_offers_job_creator ON job_offers (job_creator);
+CREATE INDEX IF NOT EXISTS idx_job_offers_deal_id ON job_offers (deal_id);
+CREATE INDEX IF NOT EXISTS idx_job_offers_state ON job_offers (state);
+
+CREATE TABLE IF NOT EXISTS resource_offers (
+	id TEXT PRIMARY KEY,
+	resource_provider TEXT NOT NULL,
+	deal_id TEXT NOT NULL,
+	state INTEGER NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_resource_offers_resource_provider ON resource_offers (resource_provider);
+CREATE INDEX IF NOT EXISTS idx_resource_offers_deal_id ON resource_offers (deal_id);
+CREATE INDEX IF NOT EXISTS idx_resource_offers_state ON resource_offers (state);
+
+CREATE TABLE IF NOT EXISTS deals (
+	id TEXT PRIMARY KEY,
+	job_creator TEXT NOT NULL,
+	resource_provider TEXT NOT NULL,
+	mediator TEXT NOT NULL,
+	state INTEGER NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_deals_job_creator ON deals (job_creator);
+CREATE INDEX IF NOT EXISTS idx_deals_resource_provider ON deals (resource_provider);
+CREATE INDEX IF NOT EXISTS idx_deals_mediator ON deals (mediator);
+CREATE INDEX IF NOT EXISTS idx_deals_state ON deals (state);
+
+CREATE TABLE IF NOT EXISTS results (
+	deal_id TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS decisions (
+	id TEXT PRIMARY KEY,
+	resource_offer TEXT NOT NULL,
+	job_offer TEXT NOT NULL,
+	data TEXT NOT NULL
+);
+`
+
+type SolverStoreSQLite struct {
+	db *sql.DB
+
+	mutex         sync.RWMutex
+	deadline      time.Duration
+	writeDeadline time.Duration
+}
+
+// NewSolverStoreSQLite opens (creating if needed) a SQLite-backed solver
+// store at path, and migrates any existing JSONL state into it on first
+// launch.
+func NewSolverStoreSQLite(path string) (*SolverStoreSQLite, error) {
+	// busy_timeout is a per-connection setting, so it goes in the DSN to
+	// apply to every connection the pool opens, not just the first one.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite solver store: %w", err)
+	}
+
+	// WAL lets readers and a writer proceed concurrently instead of
+	// exclusive-locking the whole file per write, and busy_timeout makes a
+	// writer that does collide with another wait and retry instead of
+	// failing outright with SQLITE_BUSY — both needed for the concurrent
+	// write volumes (tens of thousands of writers onboarding ~12M deals)
+	// this backend was built for.
+	if _, err := db.Exec("PRAGMA journal_mode=WAL;"); err != nil {
+		return nil, fmt.Errorf("error enabling WAL mode on sqlite solver store: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout=5000;"); err != nil {
+		return nil, fmt.Errorf("error setting busy_timeout on sqlite solver store: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("error migrating sqlite solver store schema: %w", err)
+	}
+
+	s := &SolverStoreSQLite{db: db}
+
+	if err := s.migrateFromJSONL(); err != nil {
+		return nil, fmt.Errorf("error migrating existing JSONL state into sqlite: %w", err)
+	}
+
+	return s, nil
+}
+
+// migrateFromJSONL populates a freshly created SQLite store from any
+// existing /var/tmp/lilypad_*.jsonl files, so switching --store-backend
+// from memory to sqlite doesn't lose history.
+func (s *SolverStoreSQLite) migrateFromJSONL() error {
+	ctx := context.Background()
+
+	var count int
+	if err := s.db.QueryRow("SELECT count(*) FROM job_offers").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if !memory.HasPersistedState() {
+		return nil
+	}
+
+	legacy, err := memory.NewSolverStoreMemory()
+	if err != nil {
+		return err
+	}
+	defer legacy.Close()
+
+	jobOffers, err := legacy.GetJobOffers(ctx, store.GetJobOffersQuery{})
+	if err != nil {
+		return err
+	}
+	for _, jobOffer := range jobOffers {
+		if _, err := s.AddJobOffer(ctx, jobOffer); err != nil {
+			return err
+		}
+	}
+
+	resourceOffers, err := legacy.GetResourceOffers(ctx, store.GetResourceOffersQuery{})
+	if err != nil {
+		return err
+	}
+	for _, resourceOffer := range resourceOffers {
+		if _, err := s.AddResourceOffer(ctx, resourceOffer); err != nil {
+			return err
+		}
+	}
+
+	deals, err := legacy.GetDeals(ctx, store.GetDealsQuery{})
+	if err != nil {
+		return err
+	}
+	for _, deal := range deals {
+		if _, err := s.AddDeal(ctx, deal); err != nil {
+			return err
+		}
+	}
+
+	results, err := legacy.GetResults(ctx)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if _, err := s.AddResult(ctx, result); err != nil {
+			return err
+		}
+	}
+
+	decisions, err := legacy.GetMatchDecisions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, decision := range decisions {
+		if _, err := s.AddMatchDecision(ctx, decision.ResourceOffer, decision.JobOffer, decision.Deal, decision.Result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetDeadline implements store.SolverStore.
+func (s *SolverStoreSQLite) SetDeadline(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deadline = d
+}
+
+// SetWriteDeadline implements store.SolverStore.
+func (s *SolverStoreSQLite) SetWriteDeadline(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.writeDeadline = d
+}
+
+// boundContext layers the store's default deadline on top of ctx, on top of
+// whatever deadline ctx already carries, so the operator-configured cap
+// still applies regardless of how far out the caller's own deadline is.
+// writeOp additionally layers writeDeadline on top of that, since a
+// mutating call pays for both the query and its durable write.
+func (s *SolverStoreSQLite) boundContext(ctx context.Context, writeOp bool) (context.Context, context.CancelFunc) {
+	s.mutex.RLock()
+	deadline, writeDeadline := s.deadline, s.writeDeadline
+	s.mutex.RUnlock()
+
+	cancel := func() {}
+	if deadline > 0 {
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+	}
+	if writeOp && writeDeadline > 0 {
+		var writeCancel context.CancelFunc
+		ctx, writeCancel = context.WithTimeout(ctx, writeDeadline)
+		outerCancel := cancel
+		cancel = func() {
+			writeCancel()
+			outerCancel()
+		}
+	}
+	return ctx, cancel
+}
+
+func (s *SolverStoreSQLite) AddJobOffer(ctx context.Context, jobOffer data.JobOfferContainer) (*data.JobOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	blob, err := json.Marshal(jobOffer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO job_offers (id, job_creator, deal_id, state, data) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET job_creator=excluded.job_creator, deal_id=excluded.deal_id, state=excluded.state, data=excluded.data`,
+		jobOffer.ID, jobOffer.JobCreator, jobOffer.DealID, jobOffer.State, string(blob),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &jobOffer, nil
+}
+
+// AddJobOffersBatch implements store.Tx on top of a single SQL transaction.
+func (s *SolverStoreSQLite) AddJobOffersBatch(ctx context.Context, jobOffers []data.JobOfferContainer) ([]data.JobOfferContainer, error) {
+	if len(jobOffers) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, jobOffer := range jobOffers {
+		blob, err := json.Marshal(jobOffer)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO job_offers (id, job_creator, deal_id, state, data) VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT(id) DO UPDATE SET job_creator=excluded.job_creator, deal_id=excluded.deal_id, state=excluded.state, data=excluded.data`,
+			jobOffer.ID, jobOffer.JobCreator, jobOffer.DealID, jobOffer.State, string(blob),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return jobOffers, nil
+}
+
+func (s *SolverStoreSQLite) AddResourceOffer(ctx context.Context, resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	blob, err := json.Marshal(resourceOffer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO resource_offers (id, resource_provider, deal_id, state, data) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET resource_provider=excluded.resource_provider, deal_id=excluded.deal_id, state=excluded.state, data=excluded.data`,
+		resourceOffer.ID, resourceOffer.ResourceProvider, resourceOffer.DealID, resourceOffer.State, string(blob),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceOffer, nil
+}
+
+func (s *SolverStoreSQLite) AddDeal(ctx context.Context, deal data.DealContainer) (*data.DealContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	blob, err := json.Marshal(deal)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO deals (id, job_creator, resource_provider, mediator, state, data) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET job_creator=excluded.job_creator, resource_provider=excluded.resource_provider, mediator=excluded.mediator, state=excluded.state, data=excluded.data`,
+		deal.ID, deal.JobCreator, deal.ResourceProvider, deal.Mediator, deal.State, string(blob),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deal, nil
+}
+
+func (s *SolverStoreSQLite) AddResult(ctx context.Context, result data.Result) (*data.Result, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO results (deal_id, data) VALUES (?, ?) ON CONFLICT(deal_id) DO UPDATE SET data=excluded.data`,
+		result.DealID, string(blob),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddResultsBatch implements store.Tx; see AddJobOffersBatch for the shape.
+func (s *SolverStoreSQLite) AddResultsBatch(ctx context.Context, results []data.Result) ([]data.Result, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, result := range results {
+		blob, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO results (deal_id, data) VALUES (?, ?) ON CONFLICT(deal_id) DO UPDATE SET data=excluded.data`,
+			result.DealID, string(blob),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// AddMatchDecision checks for and inserts the decision inside a single
+// transaction, so two concurrent decisions for the same pair can't both
+// observe "no existing decision" and both insert.
+func (s *SolverStoreSQLite) AddMatchDecision(ctx context.Context, resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id := fmt.Sprintf("%s-%s", resourceOffer, jobOffer)
+	var existing int
+	if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM decisions WHERE id = ?", id).Scan(&existing); err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("that match already exists")
+	}
+
+	decision := &data.MatchDecision{
+		ResourceOffer: resourceOffer,
+		JobOffer:      jobOffer,
+		Deal:          deal,
+		Result:        result,
+	}
+	blob, err := json.Marshal(decision)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO decisions (id, resource_offer, job_offer, data) VALUES (?, ?, ?, ?)",
+		id, resourceOffer, jobOffer, string(blob),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+func (s *SolverStoreSQLite) GetJobOffers(ctx context.Context, query store.GetJobOffersQuery) ([]data.JobOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	sqlQuery := "SELECT data FROM job_offers WHERE 1=1"
+	args := []any{}
+	if query.JobCreator != "" {
+		sqlQuery += " AND job_creator = ?"
+		args = append(args, query.JobCreator)
+	}
+	if query.NotMatched {
+		sqlQuery += " AND deal_id = ''"
+	}
+	return queryJobOffers(ctx, s.db, sqlQuery, args...)
+}
+
+func queryJobOffers(ctx context.Context, db *sql.DB, sqlQuery string, args ...any) ([]data.JobOfferContainer, error) {
+	rows, err := db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobOffers := []data.JobOfferContainer{}
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		var jobOffer data.JobOfferContainer
+		if err := json.Unmarshal([]byte(blob), &jobOffer); err != nil {
+			return nil, err
+		}
+		jobOffers = append(jobOffers, jobOffer)
+	}
+	return jobOffers, rows.Err()
+}
+
+func (s *SolverStoreSQLite) GetResourceOffers(ctx context.Context, query store.GetResourceOffersQuery) ([]data.ResourceOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	sqlQuery := "SELECT data FROM resource_offers WHERE 1=1"
+	args := []any{}
+	if query.ResourceProvider != "" {
+		sqlQuery += " AND resource_provider = ?"
+		args = append(args, query.ResourceProvider)
+	}
+	if query.NotMatched {
+		sqlQuery += " AND deal_id = ''"
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resourceOffers := []data.ResourceOfferContainer{}
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		var resourceOffer data.ResourceOfferContainer
+		if err := json.Unmarshal([]byte(blob), &resourceOffer); err != nil {
+			return nil, err
+		}
+		if query.Active && !data.IsActiveAgreementState(resourceOffer.State) {
+			continue
+		}
+		resourceOffers = append(resourceOffers, resourceOffer)
+	}
+	return resourceOffers, rows.Err()
+}
+
+func (s *SolverStoreSQLite) GetDeals(ctx context.Context, query store.GetDealsQuery) ([]data.DealContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	sqlQuery := "SELECT data FROM deals WHERE 1=1"
+	args := []any{}
+	if query.JobCreator != "" {
+		sqlQuery += " AND job_creator = ?"
+		args = append(args, query.JobCreator)
+	}
+	if query.ResourceProvider != "" {
+		sqlQuery += " AND resource_provider = ?"
+		args = append(args, query.ResourceProvider)
+	}
+	if query.Mediator != "" {
+		sqlQuery += " AND mediator = ?"
+		args = append(args, query.Mediator)
+	}
+	if query.State != "" {
+		state, err := data.GetAgreementState(query.State)
+		if err != nil {
+			return nil, err
+		}
+		sqlQuery += " AND state = ?"
+		args = append(args, state)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deals := []data.DealContainer{}
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		var deal data.DealContainer
+		if err := json.Unmarshal([]byte(blob), &deal); err != nil {
+			return nil, err
+		}
+		deals = append(deals, deal)
+	}
+	return deals, rows.Err()
+}
+
+func (s *SolverStoreSQLite) GetJobOffer(ctx context.Context, id string) (*data.JobOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	var blob string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM job_offers WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobOffer data.JobOfferContainer
+	if err := json.Unmarshal([]byte(blob), &jobOffer); err != nil {
+		return nil, err
+	}
+	return &jobOffer, nil
+}
+
+func (s *SolverStoreSQLite) GetResourceOffer(ctx context.Context, id string) (*data.ResourceOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	var blob string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM resource_offers WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resourceOffer data.ResourceOfferContainer
+	if err := json.Unmarshal([]byte(blob), &resourceOffer); err != nil {
+		return nil, err
+	}
+	return &resourceOffer, nil
+}
+
+func (s *SolverStoreSQLite) GetDeal(ctx context.Context, id string) (*data.DealContainer, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	var blob string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM deals WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var deal data.DealContainer
+	if err := json.Unmarshal([]byte(blob), &deal); err != nil {
+		return nil, err
+	}
+	return &deal, nil
+}
+
+func (s *SolverStoreSQLite) GetResult(ctx context.Context, id string) (*data.Result, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	var blob string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM results WHERE deal_id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result data.Result
+	if err := json.Unmarshal([]byte(blob), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *SolverStoreSQLite) GetMatchDecision(ctx context.Context, resourceOffer string, jobOffer string) (*data.MatchDecision, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	id := fmt.Sprintf("%s-%s", resourceOffer, jobOffer)
+	var blob string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM decisions WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var decision data.MatchDecision
+	if err := json.Unmarshal([]byte(blob), &decision); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// updateJobOfferTx reads the job offer for id, applies fn to it, and writes
+// it back, all inside one transaction so a concurrent update to the same row
+// can't read the same "before" state and clobber this write (the SQLite
+// analogue of SolverStoreMemory serializing the whole read-modify-write under
+// its single mutex).
+func (s *SolverStoreSQLite) updateJobOfferTx(ctx context.Context, id string, fn func(*data.JobOfferContainer)) (*data.JobOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var blob string
+	err = tx.QueryRowContext(ctx, "SELECT data FROM job_offers WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job offer not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobOffer data.JobOfferContainer
+	if err := json.Unmarshal([]byte(blob), &jobOffer); err != nil {
+		return nil, err
+	}
+
+	fn(&jobOffer)
+
+	newBlob, err := json.Marshal(jobOffer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO job_offers (id, job_creator, deal_id, state, data) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET job_creator=excluded.job_creator, deal_id=excluded.deal_id, state=excluded.state, data=excluded.data`,
+		jobOffer.ID, jobOffer.JobCreator, jobOffer.DealID, jobOffer.State, string(newBlob),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &jobOffer, nil
+}
+
+// updateResourceOfferTx is updateJobOfferTx's counterpart for resource_offers.
+func (s *SolverStoreSQLite) updateResourceOfferTx(ctx context.Context, id string, fn func(*data.ResourceOfferContainer)) (*data.ResourceOfferContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var blob string
+	err = tx.QueryRowContext(ctx, "SELECT data FROM resource_offers WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("resource offer not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resourceOffer data.ResourceOfferContainer
+	if err := json.Unmarshal([]byte(blob), &resourceOffer); err != nil {
+		return nil, err
+	}
+
+	fn(&resourceOffer)
+
+	newBlob, err := json.Marshal(resourceOffer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO resource_offers (id, resource_provider, deal_id, state, data) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET resource_provider=excluded.resource_provider, deal_id=excluded.deal_id, state=excluded.state, data=excluded.data`,
+		resourceOffer.ID, resourceOffer.ResourceProvider, resourceOffer.DealID, resourceOffer.State, string(newBlob),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &resourceOffer, nil
+}
+
+// updateDealTx is updateJobOfferTx's counterpart for deals; every
+// UpdateDeal* method below is a thin wrapper around it.
+func (s *SolverStoreSQLite) updateDealTx(ctx context.Context, id string, fn func(*data.DealContainer)) (*data.DealContainer, error) {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var blob string
+	err = tx.QueryRowContext(ctx, "SELECT data FROM deals WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("deal not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var deal data.DealContainer
+	if err := json.Unmarshal([]byte(blob), &deal); err != nil {
+		return nil, err
+	}
+
+	fn(&deal)
+
+	newBlob, err := json.Marshal(deal)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO deals (id, job_creator, resource_provider, mediator, state, data) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET job_creator=excluded.job_creator, resource_provider=excluded.resource_provider, mediator=excluded.mediator, state=excluded.state, data=excluded.data`,
+		deal.ID, deal.JobCreator, deal.ResourceProvider, deal.Mediator, deal.State, string(newBlob),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &deal, nil
+}
+
+func (s *SolverStoreSQLite) UpdateJobOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.JobOfferContainer, error) {
+	return s.updateJobOfferTx(ctx, id, func(jobOffer *data.JobOfferContainer) {
+		jobOffer.DealID = dealID
+		jobOffer.State = state
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateResourceOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.ResourceOfferContainer, error) {
+	return s.updateResourceOfferTx(ctx, id, func(resourceOffer *data.ResourceOfferContainer) {
+		resourceOffer.DealID = dealID
+		resourceOffer.State = state
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateDealState(ctx context.Context, id string, state uint8) (*data.DealContainer, error) {
+	return s.updateDealTx(ctx, id, func(deal *data.DealContainer) {
+		deal.State = state
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateDealMediator(ctx context.Context, id string, mediator string) (*data.DealContainer, error) {
+	return s.updateDealTx(ctx, id, func(deal *data.DealContainer) {
+		deal.Mediator = mediator
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateDealTransactionsResourceProvider(ctx context.Context, id string, transactions data.DealTransactionsResourceProvider) (*data.DealContainer, error) {
+	return s.updateDealTx(ctx, id, func(deal *data.DealContainer) {
+		txs := &deal.Transactions.ResourceProvider
+		if transactions.Agree != "" {
+			txs.Agree = transactions.Agree
+		}
+		if transactions.AddResult != "" {
+			txs.AddResult = transactions.AddResult
+		}
+		if transactions.TimeoutAgree != "" {
+			txs.TimeoutAgree = transactions.TimeoutAgree
+		}
+		if transactions.TimeoutJudgeResult != "" {
+			txs.TimeoutJudgeResult = transactions.TimeoutJudgeResult
+		}
+		if transactions.TimeoutMediateResult != "" {
+			txs.TimeoutMediateResult = transactions.TimeoutMediateResult
+		}
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateDealTransactionsJobCreator(ctx context.Context, id string, transactions data.DealTransactionsJobCreator) (*data.DealContainer, error) {
+	return s.updateDealTx(ctx, id, func(deal *data.DealContainer) {
+		txs := &deal.Transactions.JobCreator
+		if transactions.Agree != "" {
+			txs.Agree = transactions.Agree
+		}
+		if transactions.AcceptResult != "" {
+			txs.AcceptResult = transactions.AcceptResult
+		}
+		if transactions.CheckResult != "" {
+			txs.CheckResult = transactions.CheckResult
+		}
+		if transactions.TimeoutAgree != "" {
+			txs.TimeoutAgree = transactions.TimeoutAgree
+		}
+		if transactions.TimeoutSubmitResult != "" {
+			txs.TimeoutSubmitResult = transactions.TimeoutSubmitResult
+		}
+		if transactions.TimeoutMediateResult != "" {
+			txs.TimeoutMediateResult = transactions.TimeoutMediateResult
+		}
+	})
+}
+
+func (s *SolverStoreSQLite) UpdateDealTransactionsMediator(ctx context.Context, id string, transactions data.DealTransactionsMediator) (*data.DealContainer, error) {
+	return s.updateDealTx(ctx, id, func(deal *data.DealContainer) {
+		txs := &deal.Transactions.Mediator
+		if transactions.MediationAcceptResult != "" {
+			txs.MediationAcceptResult = transactions.MediationAcceptResult
+		}
+		if transactions.MediationRejectResult != "" {
+			txs.MediationRejectResult = transactions.MediationRejectResult
+		}
+	})
+}
+
+func (s *SolverStoreSQLite) RemoveJobOffer(ctx context.Context, id string) error {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, "DELETE FROM job_offers WHERE id = ?", id)
+	return err
+}
+
+func (s *SolverStoreSQLite) RemoveResourceOffer(ctx context.Context, id string) error {
+	ctx, cancel := s.boundContext(ctx, true)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, "DELETE FROM resource_offers WHERE id = ?", id)
+	return err
+}
+
+func (s *SolverStoreSQLite) GetLeaderboardData(ctx context.Context, query store.GetLeaderboardQuery) ([]data.LeaderboardEntry, error) {
+	ctx, cancel := s.boundContext(ctx, false)
+	defer cancel()
+
+	comparator, err := data.GetLeaderboardComparator(query.SortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := "SELECT resource_provider, state, count(*) FROM deals WHERE 1=1"
+	args := []any{}
+	if query.ResourceProvider != "" {
+		sqlQuery += " AND resource_provider = ?"
+		args = append(args, query.ResourceProvider)
+	}
+	sqlQuery += " GROUP BY resource_provider, state"
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entryMap := map[string]*data.LeaderboardEntry{}
+	for rows.Next() {
+		var resourceProvider string
+		var state uint8
+		var count int
+		if err := rows.Scan(&resourceProvider, &state, &count); err != nil {
+			return nil, err
+		}
+		entry, ok := entryMap[resourceProvider]
+		if !ok {
+			entry = &data.LeaderboardEntry{ResourceProvider: resourceProvider}
+			entryMap[resourceProvider] = entry
+		}
+		switch {
+		case data.IsSuccessfulAgreementState(state):
+			entry.JobCount += count
+		case state == data.AgreementStateResultsRejected || state == data.AgreementStateMediationRejected:
+			entry.FailureCount += count
+		case state == data.AgreementStateTimeout:
+			entry.TimeoutCount += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]data.LeaderboardEntry, 0, len(entryMap))
+	for _, entry := range entryMap {
+		results = append(results, *entry)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return comparator(results[i], results[j]) < 0
+	})
+
+	if query.Limit > 0 && query.Limit < len(results) {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// Compile-time interface check:
+var _ store.SolverStore = (*SolverStoreSQLite)(nil)
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+	"github.com/bacalhau-project/lilypad/pkg/solver/store"
+)
+
+func newTestStore(t *testing.T) *SolverStoreSQLite {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "solver.db")
+	s, err := NewSolverStoreSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Runs concurrent UpdateJobOfferState calls against the same job offer and
+// checks the row is still readable afterwards with the deal_id they all set.
+// Every call writes the same deal_id, so this does not detect a lost update
+// between two read-modify-write cycles.
+func TestUpdateJobOfferStateIsAtomicUnderConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	if _, err := s.AddJobOffer(ctx, data.JobOfferContainer{ID: "offer1", JobCreator: "jc1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	const concurrency = 20
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = s.UpdateJobOfferState(ctx, "offer1", "deal1", uint8(i%256))
+		}(i)
+	}
+	wg.Wait()
+
+	jobOffer, err := s.GetJobOffer(ctx, "offer1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobOffer.DealID != "deal1" {
+		t.Fatalf("expected every concurrent update to agree on deal_id, got %q", jobOffer.DealID)
+	}
+}
+
+func TestAddMatchDecisionRejectsDuplicate(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	if _, err := s.AddMatchDecision(ctx, "ro1", "jo1", "deal1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddMatchDecision(ctx, "ro1", "jo1", "deal1", true); err == nil {
+		t.Fatal("expected a second decision for the same resource offer/job offer pair to be rejected")
+	}
+}
+
+func TestGetJobOffersFiltersNotMatchedUsingDealIDIndex(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	if _, err := s.AddJobOffer(ctx, data.JobOfferContainer{ID: "unmatched", JobCreator: "jc1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddJobOffer(ctx, data.JobOfferContainer{ID: "matched", JobCreator: "jc1", DealID: "deal1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobOffers, err := s.GetJobOffers(ctx, store.GetJobOffersQuery{NotMatched: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobOffers) != 1 || jobOffers[0].ID != "unmatched" {
+		t.Fatalf("expected only the unmatched job offer, got %+v", jobOffers)
+	}
+}
+
+// Regression test for SQLITE_BUSY/"database is locked" errors under
+// concurrent writers (see TestUpdateJobOfferStateIsAtomicUnderConcurrentUpdates):
+// NewSolverStoreSQLite must enable WAL mode and a busy_timeout rather than
+// leaving the connection on sqlite's rollback-journal default.
+func TestNewSolverStoreSQLiteEnablesWALAndBusyTimeout(t *testing.T) {
+	s := newTestStore(t)
+
+	var journalMode string
+	if err := s.db.QueryRow("PRAGMA journal_mode;").Scan(&journalMode); err != nil {
+		t.Fatal(err)
+	}
+	if journalMode != "wal" {
+		t.Fatalf("expected journal_mode=wal, got %q", journalMode)
+	}
+
+	var busyTimeout int
+	if err := s.db.QueryRow("PRAGMA busy_timeout;").Scan(&busyTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if busyTimeout <= 0 {
+		t.Fatalf("expected a positive busy_timeout, got %d", busyTimeout)
+	}
+}
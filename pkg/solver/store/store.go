@@ -0,0 +1,101 @@
+// Package store defines the persistence interface the solver uses to track
+// job offers, resource offers, deals, results and match decisions.
+// Implementations live in sub-packages (memory, sqlite, ...).
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/lilypad/pkg/data"
+)
+
+type GetJobOffersQuery struct {
+	JobCreator string
+	NotMatched bool
+}
+
+type GetResourceOffersQuery struct {
+	ResourceProvider string
+	Active           bool
+	NotMatched       bool
+}
+
+type GetDealsQuery struct {
+	JobCreator       string
+	ResourceProvider string
+	Mediator         string
+	State            string
+}
+
+// GetLeaderboardQuery selects, sorts and paginates a leaderboard read.
+// SortBy names a comparator from the data package's leaderboard registry
+// (data.ByJobCount and friends), defaulting to job count when empty.
+type GetLeaderboardQuery struct {
+	SortBy           string
+	Limit            int
+	ResourceProvider string
+}
+
+// Tx is the batch-write surface of a SolverStore: each call commits all of
+// its records atomically under a single mutex acquisition and a single
+// durable write, instead of one lock-and-flush per record.
+type Tx interface {
+	AddJobOffersBatch(ctx context.Context, jobOffers []data.JobOfferContainer) ([]data.JobOfferContainer, error)
+	AddResultsBatch(ctx context.Context, results []data.Result) ([]data.Result, error)
+}
+
+// SolverStore is the full read/write surface the solver needs against its
+// backing store, regardless of what's behind it (in-memory + JSONL, SQLite,
+// BoltDB, ...).
+type SolverStore interface {
+	Tx
+
+	AddJobOffer(ctx context.Context, jobOffer data.JobOfferContainer) (*data.JobOfferContainer, error)
+	AddResourceOffer(ctx context.Context, resourceOffer data.ResourceOfferContainer) (*data.ResourceOfferContainer, error)
+	AddDeal(ctx context.Context, deal data.DealContainer) (*data.DealContainer, error)
+	AddResult(ctx context.Context, result data.Result) (*data.Result, error)
+	AddMatchDecision(ctx context.Context, resourceOffer string, jobOffer string, deal string, result bool) (*data.MatchDecision, error)
+
+	GetJobOffers(ctx context.Context, query GetJobOffersQuery) ([]data.JobOfferContainer, error)
+	GetResourceOffers(ctx context.Context, query GetResourceOffersQuery) ([]data.ResourceOfferContainer, error)
+	GetDeals(ctx context.Context, query GetDealsQuery) ([]data.DealContainer, error)
+	GetJobOffer(ctx context.Context, id string) (*data.JobOfferContainer, error)
+	GetResourceOffer(ctx context.Context, id string) (*data.ResourceOfferContainer, error)
+	GetDeal(ctx context.Context, id string) (*data.DealContainer, error)
+	GetResult(ctx context.Context, id string) (*data.Result, error)
+	GetMatchDecision(ctx context.Context, resourceOffer string, jobOffer string) (*data.MatchDecision, error)
+
+	UpdateJobOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.JobOfferContainer, error)
+	UpdateResourceOfferState(ctx context.Context, id string, dealID string, state uint8) (*data.ResourceOfferContainer, error)
+	UpdateDealState(ctx context.Context, id string, state uint8) (*data.DealContainer, error)
+	UpdateDealMediator(ctx context.Context, id string, mediator string) (*data.DealContainer, error)
+	UpdateDealTransactionsResourceProvider(ctx context.Context, id string, transactions data.DealTransactionsResourceProvider) (*data.DealContainer, error)
+	UpdateDealTransactionsJobCreator(ctx context.Context, id string, transactions data.DealTransactionsJobCreator) (*data.DealContainer, error)
+	UpdateDealTransactionsMediator(ctx context.Context, id string, transactions data.DealTransactionsMediator) (*data.DealContainer, error)
+
+	RemoveJobOffer(ctx context.Context, id string) error
+	RemoveResourceOffer(ctx context.Context, id string) error
+
+	GetLeaderboardData(ctx context.Context, query GetLeaderboardQuery) ([]data.LeaderboardEntry, error)
+
+	// SetDeadline caps how long a call will wait to acquire the store, even
+	// if the caller's own context carries a later deadline. Zero disables
+	// the default cap.
+	SetDeadline(d time.Duration)
+	// SetWriteDeadline caps how long a mutating call will wait for its
+	// write to durably land, on top of SetDeadline. Zero disables the
+	// default cap.
+	SetWriteDeadline(d time.Duration)
+}
+
+// Backend is a SolverStore implementation a solver can be configured with.
+type Backend = SolverStore
+
+// BackendKind selects which Backend a solver should use.
+type BackendKind string
+
+const (
+	BackendKindMemory BackendKind = "memory"
+	BackendKindSQLite BackendKind = "sqlite"
+)